@@ -0,0 +1,105 @@
+package tlockagent
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/eklairs/tlock/tlock-internal/paths"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Error representing that no agent is running for the user
+var ERR_AGENT_NOT_RUNNING = errors.New("No agent is running for this user")
+
+// Sends the request to the agent of the user and returns its response
+func send(username string, req request) (response, error) {
+	var resp response
+
+	// Connect
+	conn, err := net.DialTimeout("unix", paths.AgentSocketFor(username), time.Second)
+	if err != nil {
+		return resp, ERR_AGENT_NOT_RUNNING
+	}
+
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	// Send
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return resp, err
+	}
+
+	// Receive
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return resp, err
+	}
+
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+
+	return resp, nil
+}
+
+// Returns the folders of the user's vault, without the secrets of the tokens
+func List(username string) ([]tlockvault.Folder, error) {
+	resp, err := send(username, request{Op: OpList})
+
+	return resp.Folders, err
+}
+
+// Returns the current code of the token and the seconds before it changes
+func Code(username, id string) (string, int, error) {
+	resp, err := send(username, request{Op: OpCode, ID: id})
+
+	return resp.Code, resp.Remaining, err
+}
+
+// Adds the token to the folder of the user's vault and returns it without its secret
+func Add(username, folder string, token tlockvault.Token) (tlockvault.Token, error) {
+	resp, err := send(username, request{Op: OpAdd, Folder: folder, Token: &token})
+	if err != nil {
+		return tlockvault.Token{}, err
+	}
+
+	return *resp.Token, nil
+}
+
+// Imports the tokens into the user's vault, or only works out what importing would do
+// The tokens in the returned summary have no secrets
+func Import(username string, imported tlockvault.Import, dryRun bool) (tlockvault.ImportSummary, error) {
+	resp, err := send(username, request{Op: OpImport, Import: &imported, DryRun: dryRun})
+	if err != nil {
+		return tlockvault.ImportSummary{}, err
+	}
+
+	return *resp.Summary, nil
+}
+
+// Locks the vault by stopping the agent of the user
+func Lock(username string) error {
+	_, err := send(username, request{Op: OpLock})
+
+	return err
+}
+
+// Agent of a user, serving the vault for `tlockvault.LoadRemote`
+type remote string
+
+// Returns the agent of the user as the remote of a vault
+func Remote(username string) tlockvault.Remote {
+	return remote(username)
+}
+
+// Returns the folders of the vault, without the secrets of the tokens
+func (username remote) List() ([]tlockvault.Folder, error) {
+	return List(string(username))
+}
+
+// Returns the current code of the token and the seconds before it changes
+func (username remote) Code(id string) (string, int, error) {
+	return Code(string(username), id)
+}
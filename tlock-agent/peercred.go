@@ -0,0 +1,25 @@
+package tlockagent
+
+import (
+	"errors"
+	"net"
+)
+
+// Error representing that the platform cannot tell who is on the other side of the socket
+var ERR_PEER_CREDENTIALS_UNSUPPORTED = errors.New("The agent is not supported on this platform, it cannot check who connects to it")
+
+// Returns the UID of the process on the other end of the connection
+func peerUID(conn *net.UnixConn) (int, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return -1, err
+	}
+
+	uid, credErr := -1, error(nil)
+
+	if err := raw.Control(func(fd uintptr) { uid, credErr = socketPeerUID(int(fd)) }); err != nil {
+		return -1, err
+	}
+
+	return uid, credErr
+}
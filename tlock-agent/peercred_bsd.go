@@ -0,0 +1,18 @@
+//go:build darwin || freebsd
+
+package tlockagent
+
+import "golang.org/x/sys/unix"
+
+// Whether the platform can tell who is on the other side of the socket
+const peerCredentialsSupported = true
+
+// Reads the UID of the peer with LOCAL_PEERCRED
+func socketPeerUID(fd int) (int, error) {
+	cred, err := unix.GetsockoptXucred(fd, unix.SOL_LOCAL, unix.LOCAL_PEERCRED)
+	if err != nil {
+		return -1, err
+	}
+
+	return int(cred.Uid), nil
+}
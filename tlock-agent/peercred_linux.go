@@ -0,0 +1,18 @@
+//go:build linux
+
+package tlockagent
+
+import "golang.org/x/sys/unix"
+
+// Whether the platform can tell who is on the other side of the socket
+const peerCredentialsSupported = true
+
+// Reads the UID of the peer with SO_PEERCRED
+func socketPeerUID(fd int) (int, error) {
+	cred, err := unix.GetsockoptUcred(fd, unix.SOL_SOCKET, unix.SO_PEERCRED)
+	if err != nil {
+		return -1, err
+	}
+
+	return int(cred.Uid), nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd
+
+package tlockagent
+
+// Whether the platform can tell who is on the other side of the socket
+const peerCredentialsSupported = false
+
+// Peer credentials are not available, so no one is trusted
+func socketPeerUID(fd int) (int, error) {
+	return -1, ERR_PEER_CREDENTIALS_UNSUPPORTED
+}
@@ -0,0 +1,59 @@
+package tlockagent
+
+import (
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Operations understood by the agent
+const (
+	// Lists the folders and tokens, without their secrets
+	OpList = "list"
+
+	// Returns the current code of a token
+	OpCode = "code"
+
+	// Adds a token to a folder
+	OpAdd = "add"
+
+	// Imports tokens, creating the folders that don't exist yet
+	OpImport = "import"
+
+	// Forgets the vault and stops the agent
+	OpLock = "lock"
+)
+
+// Request sent to the agent, one per connection
+type request struct {
+	// Operation
+	Op string `json:"op"`
+
+	// ID of the token, for `OpCode`
+	ID string `json:"id,omitempty"`
+
+	// Folder and token to add, for `OpAdd`
+	Folder string            `json:"folder,omitempty"`
+	Token  *tlockvault.Token `json:"token,omitempty"`
+
+	// Tokens to import and whether to only plan the import, for `OpImport`
+	Import *tlockvault.Import `json:"import,omitempty"`
+	DryRun bool               `json:"dry_run,omitempty"`
+}
+
+// Response of the agent
+type response struct {
+	// Error message, if the request failed
+	Error string `json:"error,omitempty"`
+
+	// Folders, for `OpList`
+	Folders []tlockvault.Folder `json:"folders,omitempty"`
+
+	// Current code and seconds before it changes, for `OpCode`
+	Code      string `json:"code,omitempty"`
+	Remaining int    `json:"remaining,omitempty"`
+
+	// Added token without its secret, for `OpAdd`
+	Token *tlockvault.Token `json:"token,omitempty"`
+
+	// What the import did without the secrets of the tokens, for `OpImport`
+	Summary *tlockvault.ImportSummary `json:"summary,omitempty"`
+}
@@ -0,0 +1,316 @@
+package tlockagent
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	tlockotp "github.com/eklairs/tlock/tlock-otp"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Error representing that an agent is already running for the user
+var ERR_AGENT_RUNNING = errors.New("An agent is already running for this user")
+
+// Error representing that the agent does not know the operation
+var ERR_UNKNOWN_OP = errors.New("Unknown agent operation")
+
+// Error representing that the token does not exist in the vault
+var ERR_TOKEN_NOT_FOUND = errors.New("Token does not exist in the vault")
+
+// Error representing that the request is missing what the operation needs
+var ERR_BAD_REQUEST = errors.New("Malformed agent request")
+
+// How long a client gets to send its request
+const requestTimeout = 5 * time.Second
+
+// Agent that holds an unlocked vault and serves it over a Unix socket
+type Agent struct {
+	// Unlocked vault
+	vault *tlockvault.Vault
+
+	// Listener of the socket
+	listener *net.UnixListener
+
+	// Serializes the access to the vault
+	lock sync.Mutex
+
+	// Closed when the agent stops
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Starts listening on the socket for the vault
+// Only the owner can access the socket, and the connections from other users are rejected as well
+func Listen(vault *tlockvault.Vault, socket string) (*Agent, error) {
+	// The socket is useless if we cannot check who connects to it
+	if !peerCredentialsSupported {
+		return nil, ERR_PEER_CREDENTIALS_UNSUPPORTED
+	}
+
+	// Private directory for the sockets
+	if err := os.MkdirAll(filepath.Dir(socket), 0o700); err != nil {
+		return nil, err
+	}
+
+	// Remove the socket of an agent that did not exit cleanly, but leave a running one alone
+	if _, err := os.Stat(socket); err == nil {
+		if conn, err := net.DialTimeout("unix", socket, time.Second); err == nil {
+			conn.Close()
+
+			return nil, ERR_AGENT_RUNNING
+		}
+
+		os.Remove(socket)
+	}
+
+	// Listen
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: socket, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+
+	// Restrict permissions
+	if err := os.Chmod(socket, 0o600); err != nil {
+		listener.Close()
+
+		return nil, err
+	}
+
+	return &Agent{vault: vault, listener: listener, done: make(chan struct{})}, nil
+}
+
+// Serves the requests until the agent is locked, stopped or its lifetime runs out
+// A lifetime of zero keeps the agent around until it is locked
+func (agent *Agent) Serve(lifetime time.Duration) error {
+	// Stop after the lifetime
+	if lifetime > 0 {
+		timer := time.AfterFunc(lifetime, agent.Stop)
+		defer timer.Stop()
+	}
+
+	for {
+		conn, err := agent.listener.AcceptUnix()
+		if err != nil {
+			// Stopped
+			select {
+			case <-agent.done:
+				return nil
+			default:
+				return err
+			}
+		}
+
+		go agent.handle(conn)
+	}
+}
+
+// Stops the agent and removes its socket
+func (agent *Agent) Stop() {
+	agent.stopOnce.Do(func() {
+		close(agent.done)
+
+		// Closing the listener removes the socket file as well
+		agent.listener.Close()
+	})
+}
+
+// Handles a single connection
+func (agent *Agent) handle(conn *net.UnixConn) {
+	defer conn.Close()
+
+	// Only the user who started the agent may talk to it
+	if uid, err := peerUID(conn); err != nil || uid != os.Getuid() {
+		return
+	}
+
+	conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	// Read request
+	var req request
+
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+
+	// Respond
+	resp := agent.respond(req)
+
+	json.NewEncoder(conn).Encode(resp)
+
+	// Lock after responding, so that the client knows that it went fine
+	if req.Op == OpLock {
+		agent.Stop()
+	}
+}
+
+// Builds the response to the request
+func (agent *Agent) respond(req request) response {
+	agent.lock.Lock()
+	defer agent.lock.Unlock()
+
+	// Pick up the changes made by other instances
+	if err := agent.reload(); err != nil {
+		return response{Error: err.Error()}
+	}
+
+	switch req.Op {
+	case OpList:
+		return response{Folders: withoutSecrets(agent.vault.Folders)}
+
+	case OpCode:
+		for _, folder := range agent.vault.Folders {
+			for _, token := range folder.Tokens {
+				if token.ID != req.ID {
+					continue
+				}
+
+				resp := response{Code: tlockotp.CurrentCode(token)}
+
+				if token.Type.TimeBased() {
+					resp.Remaining = tlockotp.RemainingTime(token)
+				}
+
+				return resp
+			}
+		}
+
+		return response{Error: ERR_TOKEN_NOT_FOUND.Error()}
+
+	case OpAdd:
+		if req.Token == nil {
+			return response{Error: ERR_BAD_REQUEST.Error()}
+		}
+
+		err := agent.change(func() error { return agent.vault.AddTokenFromToken(req.Folder, *req.Token) })
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+
+		// The new token is the last one in the folder
+		tokens := agent.vault.GetTokens(req.Folder)
+		added := withoutSecret(tokens[len(tokens)-1])
+
+		return response{Token: &added}
+
+	case OpImport:
+		if req.Import == nil {
+			return response{Error: ERR_BAD_REQUEST.Error()}
+		}
+
+		// Only plan it
+		if req.DryRun {
+			summary := withoutSecretsSummary(agent.vault.PlanImport(*req.Import))
+
+			return response{Summary: &summary}
+		}
+
+		var summary tlockvault.ImportSummary
+
+		err := agent.change(func() (err error) {
+			summary, err = agent.vault.ApplyImport(*req.Import)
+
+			return err
+		})
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+
+		summary = withoutSecretsSummary(summary)
+
+		return response{Summary: &summary}
+
+	case OpLock:
+		return response{}
+	}
+
+	return response{Error: ERR_UNKNOWN_OP.Error()}
+}
+
+// Picks up the changes made to the vault file by other instances
+func (agent *Agent) reload() error {
+	if !agent.vault.ChangedOnDisk() {
+		return nil
+	}
+
+	err := agent.vault.Reload()
+
+	// The vault was re-encrypted with another key, it has to be unlocked again
+	if errors.Is(err, tlockvault.ERR_VAULT_KEY_CHANGED) {
+		agent.Stop()
+	}
+
+	return err
+}
+
+// Applies a change to the vault while holding its lock, so that it does not overwrite the changes of other instances
+// The vault is read-only again afterwards, so that the interface can still take the lock
+func (agent *Agent) change(apply func() error) error {
+	if err := agent.vault.AcquireLock(); err != nil {
+		return err
+	}
+
+	// Another instance might have written it before we got the lock
+	err := agent.reload()
+
+	if err == nil {
+		err = apply()
+	}
+
+	if releaseErr := agent.vault.ReleaseLock(); err == nil {
+		err = releaseErr
+	}
+
+	// Go back to what is on the disk, the change did not make it there
+	if err != nil {
+		agent.vault.Reload()
+	}
+
+	return err
+}
+
+// Returns the token with its secret and PIN removed
+func withoutSecret(token tlockvault.Token) tlockvault.Token {
+	token.Secret = ""
+	token.PIN = ""
+
+	return token
+}
+
+// Returns a copy of the folders with the secrets of the tokens removed
+func withoutSecrets(folders []tlockvault.Folder) []tlockvault.Folder {
+	stripped := make([]tlockvault.Folder, len(folders))
+
+	for i, folder := range folders {
+		stripped[i] = tlockvault.Folder{Name: folder.Name, Tokens: make([]tlockvault.Token, len(folder.Tokens))}
+
+		for j, token := range folder.Tokens {
+			stripped[i].Tokens[j] = withoutSecret(token)
+		}
+	}
+
+	return stripped
+}
+
+// Returns a copy of the import summary with the secrets of the tokens removed
+func withoutSecretsSummary(summary tlockvault.ImportSummary) tlockvault.ImportSummary {
+	strip := func(entries []tlockvault.ImportEntry) []tlockvault.ImportEntry {
+		stripped := make([]tlockvault.ImportEntry, len(entries))
+
+		for i, entry := range entries {
+			entry.Token = withoutSecret(entry.Token)
+			stripped[i] = entry
+		}
+
+		return stripped
+	}
+
+	summary.Added = strip(summary.Added)
+	summary.Duplicates = strip(summary.Duplicates)
+
+	return summary
+}
@@ -0,0 +1,85 @@
+package tlockagent
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Secret material of the tokens in the test vault, none of it may leave the agent
+var secrets = []string{"0123456789abcdef", "fedcba9876543210", "00112233445566778899", "4242"}
+
+// Returns an agent for a vault that holds a mOTP token, unlocked the way `tlock agent` does it
+func newTestAgent(t *testing.T) *Agent {
+	path := filepath.Join(t.TempDir(), "vault.dat")
+
+	vault, err := tlockvault.Initialize(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vault.AddFolder("Work")
+	vault.AddTokenFromToken("Work", tlockvault.Token{Type: tlockvault.TokenTypeMOTP, Account: "alice", Secret: secrets[0], PIN: secrets[3], Period: 10, Digits: 6})
+	vault.Close()
+
+	vault, err = tlockvault.LoadReadOnly(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { vault.Close() })
+
+	return &Agent{vault: vault, done: make(chan struct{})}
+}
+
+func TestResponsesHaveNoSecrets(t *testing.T) {
+	agent := newTestAgent(t)
+
+	added := tlockvault.Token{Type: tlockvault.TokenTypeMOTP, Account: "bob", Secret: secrets[1], PIN: secrets[3], Period: 10, Digits: 6}
+	imported := tlockvault.Import{Entries: []tlockvault.ImportEntry{
+		{Folder: "Work", Token: tlockvault.Token{Type: tlockvault.TokenTypeMOTP, Account: "alice", Secret: secrets[0], PIN: secrets[3]}},
+		{Folder: "Home", Token: tlockvault.Token{Type: tlockvault.TokenTypeMOTP, Account: "carol", Secret: secrets[2], PIN: secrets[3]}},
+	}}
+
+	tests := []struct {
+		name string
+		req  request
+	}{
+		{"list", request{Op: OpList}},
+		{"add", request{Op: OpAdd, Folder: "Work", Token: &added}},
+		{"import dry run", request{Op: OpImport, Import: &imported, DryRun: true}},
+		{"import", request{Op: OpImport, Import: &imported}},
+		{"list after changes", request{Op: OpList}},
+	}
+
+	for _, test := range tests {
+		resp := agent.respond(test.req)
+
+		if resp.Error != "" {
+			t.Fatalf("%s: %s", test.name, resp.Error)
+		}
+
+		raw, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, secret := range secrets {
+			if strings.Contains(strings.ToLower(string(raw)), secret) {
+				t.Errorf("%s: response contains %q: %s", test.name, secret, raw)
+			}
+		}
+	}
+
+	// The secrets are still in the vault, only the responses leave them out
+	for _, folder := range agent.vault.Folders {
+		for _, token := range folder.Tokens {
+			if token.Secret == "" || token.PIN == "" {
+				t.Errorf("%s: secret or PIN lost in the vault", token.Account)
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Error representing that no clipboard backend can be used in this session
+var NO_BACKEND_ERR = errors.New("Clipboard is not available, pick a clipboard backend in the config")
+
+// Error representing that the configured backend does not exist
+var UNKNOWN_BACKEND_ERR = errors.New("Unknown clipboard backend")
+
+// Error representing that the configured backend cannot be used in this session
+var BACKEND_UNAVAILABLE_ERR = errors.New("Clipboard backend is not available")
+
+// Error representing that the backend cannot read the clipboard
+var READ_UNSUPPORTED_ERR = errors.New("Clipboard backend cannot read the clipboard")
+
+// Backend names
+const (
+	BackendAuto    = "auto"
+	BackendNative  = "native"
+	BackendOSC52   = "osc52"
+	BackendCommand = "command"
+)
+
+// A way to reach the clipboard
+type Backend interface {
+	// Name of the backend
+	Name() string
+
+	// Whether it can be used in this session
+	Available() bool
+
+	// Copies a secret to the clipboard
+	// Where the backend supports it, clipboard managers and the clipboard history are asked to not keep it
+	WriteSecret(value string) error
+
+	// Whether the backend can read the clipboard back
+	Readable() bool
+
+	// Reads the clipboard
+	Read() (string, error)
+
+	// Empties the clipboard
+	Clear() error
+}
+
+// Picks the backend with the given name
+// The automatic choice goes through the configured command, the native clipboard and then OSC 52
+func Select(name string, copyCommand, pasteCommand []string) (Backend, error) {
+	command := commandBackend{copyCommand: copyCommand, pasteCommand: pasteCommand}
+
+	var backend Backend
+
+	switch name {
+	case "", BackendAuto:
+		for _, backend := range []Backend{command, nativeBackend{}, osc52Backend{}} {
+			if backend.Available() {
+				return backend, nil
+			}
+		}
+
+		return nil, NO_BACKEND_ERR
+
+	case BackendNative:
+		backend = nativeBackend{}
+
+	case BackendOSC52:
+		backend = osc52Backend{}
+
+	case BackendCommand:
+		backend = command
+
+	default:
+		return nil, fmt.Errorf("%w: %s", UNKNOWN_BACKEND_ERR, name)
+	}
+
+	if !backend.Available() {
+		return nil, fmt.Errorf("%w: %s", BACKEND_UNAVAILABLE_ERR, backend.Name())
+	}
+
+	return backend, nil
+}
+
+// Value that is waiting to be cleared from the clipboard, along with the backend it was copied with
+var pending = struct {
+	lock    sync.Mutex
+	value   string
+	backend Backend
+}{}
+
+// Clears the clipboard if it still holds the value
+// Backends that cannot read the clipboard, like OSC 52, clear it anyway, as leaving the code behind is worse
+// It returns true if the clipboard was cleared
+func ClearIf(backend Backend, value string) (bool, error) {
+	if !backend.Readable() {
+		return true, backend.Clear()
+	}
+
+	// Something else has been copied since, leave it alone
+	if current, err := backend.Read(); err != nil || current != value {
+		return false, err
+	}
+
+	return true, backend.Clear()
+}
+
+// Waits for the delay and then clears the clipboard if it still holds the value
+// It returns true if the clipboard was cleared
+func ClearAfter(backend Backend, value string, delay time.Duration) bool {
+	pending.lock.Lock()
+	pending.value = value
+	pending.backend = backend
+	pending.lock.Unlock()
+
+	time.Sleep(delay)
+
+	pending.lock.Lock()
+	defer pending.lock.Unlock()
+
+	// A newer copy takes over
+	if pending.value != value {
+		return false
+	}
+
+	pending.value = ""
+
+	cleared, _ := ClearIf(backend, value)
+
+	return cleared
+}
+
+// Clears the value waiting to be cleared right away, if the clipboard still holds it
+// Called before exiting, so that the codes do not outlive tlock
+func ClearPending() {
+	pending.lock.Lock()
+	defer pending.lock.Unlock()
+
+	if pending.value != "" {
+		ClearIf(pending.backend, pending.value)
+
+		pending.value = ""
+	}
+}
@@ -0,0 +1,66 @@
+package clipboard
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// Clipboard reached through external commands, like `wl-copy` and `wl-paste` or `xclip`
+type commandBackend struct {
+	// Command that copies its stdin
+	copyCommand []string
+
+	// Command that prints the clipboard, optional
+	pasteCommand []string
+}
+
+// Name
+func (commandBackend) Name() string {
+	return BackendCommand
+}
+
+// Available if the copy command is configured and can be found
+func (backend commandBackend) Available() bool {
+	if len(backend.copyCommand) == 0 {
+		return false
+	}
+
+	_, err := exec.LookPath(backend.copyCommand[0])
+
+	return err == nil
+}
+
+// WriteSecret
+// The command decides itself if it marks the contents as sensitive
+// xclip and wl-copy cannot, see `writeSecret` of the native backend
+func (backend commandBackend) WriteSecret(value string) error {
+	command := exec.Command(backend.copyCommand[0], backend.copyCommand[1:]...)
+	command.Stdin = strings.NewReader(value)
+
+	return command.Run()
+}
+
+// Readable if there is a paste command
+func (backend commandBackend) Readable() bool {
+	return len(backend.pasteCommand) != 0
+}
+
+// Read
+func (backend commandBackend) Read() (string, error) {
+	if !backend.Readable() {
+		return "", READ_UNSUPPORTED_ERR
+	}
+
+	output, err := exec.Command(backend.pasteCommand[0], backend.pasteCommand[1:]...).Output()
+	if err != nil {
+		return "", err
+	}
+
+	// Some paste commands add a newline at the end
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+// Clear by copying nothing
+func (backend commandBackend) Clear() error {
+	return backend.WriteSecret("")
+}
@@ -0,0 +1,36 @@
+package clipboard
+
+import "github.com/atotto/clipboard"
+
+// Clipboard of the desktop, through the platform APIs or tools like xclip, xsel and wl-copy
+type nativeBackend struct{}
+
+// Name
+func (nativeBackend) Name() string {
+	return BackendNative
+}
+
+// Available if a clipboard tool was found and there is a desktop session to talk to
+func (nativeBackend) Available() bool {
+	return !clipboard.Unsupported && hasDesktopSession()
+}
+
+// WriteSecret
+func (nativeBackend) WriteSecret(value string) error {
+	return writeSecret(value)
+}
+
+// Readable
+func (nativeBackend) Readable() bool {
+	return true
+}
+
+// Read
+func (nativeBackend) Read() (string, error) {
+	return clipboard.ReadAll()
+}
+
+// Clear
+func (nativeBackend) Clear() error {
+	return empty()
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package clipboard
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/atotto/clipboard"
+)
+
+// macOS always has a clipboard, the others need an X11 or Wayland session
+// Servers reached over SSH usually have neither, even if xclip happens to be installed
+func hasDesktopSession() bool {
+	return runtime.GOOS == "darwin" || os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// The clipboard tools used on this platform cannot mark the contents as sensitive
+// Clipboard managers on Linux leave out the contents offered along with `x-kde-passwordManagerHint: secret`, which
+// `wl-paste --watch` passes on to them as `CLIPBOARD_STATE=sensitive`, but xclip, xsel and wl-copy offer a single type,
+// so the hint cannot be added next to the code. On macOS, pbcopy cannot add `org.nspasteboard.ConcealedType` either
+func writeSecret(value string) error {
+	return clipboard.WriteAll(value)
+}
+
+// Empties the clipboard
+func empty() error {
+	return clipboard.WriteAll("")
+}
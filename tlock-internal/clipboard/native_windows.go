@@ -0,0 +1,137 @@
+//go:build windows
+
+package clipboard
+
+import (
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32   = windows.NewLazySystemDLL("user32.dll")
+	kernel32 = windows.NewLazySystemDLL("kernel32.dll")
+
+	openClipboard           = user32.NewProc("OpenClipboard")
+	closeClipboard          = user32.NewProc("CloseClipboard")
+	emptyClipboard          = user32.NewProc("EmptyClipboard")
+	setClipboardData        = user32.NewProc("SetClipboardData")
+	registerClipboardFormat = user32.NewProc("RegisterClipboardFormatW")
+
+	globalAlloc   = kernel32.NewProc("GlobalAlloc")
+	globalFree    = kernel32.NewProc("GlobalFree")
+	globalLock    = kernel32.NewProc("GlobalLock")
+	globalUnlock  = kernel32.NewProc("GlobalUnlock")
+	rtlMoveMemory = kernel32.NewProc("RtlMoveMemory")
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+// Formats that keep the contents out of the clipboard history, the cloud clipboard and the clipboard managers
+var exclusionFormats = []string{
+	"ExcludeClipboardContentFromMonitorProcessing",
+	"CanIncludeInClipboardHistory",
+	"CanUploadToCloudClipboard",
+}
+
+// Windows always has a clipboard
+func hasDesktopSession() bool {
+	return true
+}
+
+// Opens the clipboard, retrying for a bit as another program may be holding it
+func open() error {
+	var err error
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		var opened uintptr
+
+		if opened, _, err = openClipboard.Call(0); opened != 0 {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// Copies the data into a global memory object and hands it to the clipboard
+// The clipboard must be open
+func setData(format uintptr, data []byte) error {
+	handle, _, err := globalAlloc.Call(gmemMoveable, uintptr(len(data)))
+	if handle == 0 {
+		return err
+	}
+
+	// Copy
+	memory, _, err := globalLock.Call(handle)
+	if memory == 0 {
+		globalFree.Call(handle)
+
+		return err
+	}
+
+	rtlMoveMemory.Call(memory, uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)))
+	globalUnlock.Call(handle)
+
+	// The clipboard owns the memory once this succeeds
+	if result, _, err := setClipboardData.Call(format, handle); result == 0 {
+		globalFree.Call(handle)
+
+		return err
+	}
+
+	return nil
+}
+
+// Copies the value along with the formats that exclude it from the clipboard history and monitors
+func writeSecret(value string) error {
+	text, err := windows.UTF16FromString(value)
+	if err != nil {
+		return err
+	}
+
+	if err := open(); err != nil {
+		return err
+	}
+
+	defer closeClipboard.Call()
+
+	if result, _, err := emptyClipboard.Call(); result == 0 {
+		return err
+	}
+
+	// Text
+	if err := setData(cfUnicodeText, unsafe.Slice((*byte)(unsafe.Pointer(&text[0])), len(text)*2)); err != nil {
+		return err
+	}
+
+	// Exclusion hints, a DWORD of zero means no for the ones that are asked as a question
+	for _, name := range exclusionFormats {
+		namePtr, _ := windows.UTF16PtrFromString(name)
+
+		if format, _, _ := registerClipboardFormat.Call(uintptr(unsafe.Pointer(namePtr))); format != 0 {
+			setData(format, make([]byte, 4))
+		}
+	}
+
+	return nil
+}
+
+// Empties the clipboard
+func empty() error {
+	if err := open(); err != nil {
+		return err
+	}
+
+	defer closeClipboard.Call()
+
+	if result, _, err := emptyClipboard.Call(); result == 0 {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package clipboard
+
+import (
+	"os"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	"golang.org/x/term"
+)
+
+// Clipboard of the terminal emulator, set through the OSC 52 escape sequence
+// Works over SSH, as the terminal on the local machine does the copying
+type osc52Backend struct{}
+
+// Wraps the sequence so that it passes through tmux or screen to the outer terminal
+func passthrough(seq osc52.Sequence) osc52.Sequence {
+	if os.Getenv("TMUX") != "" {
+		return seq.Tmux()
+	}
+
+	if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		return seq.Screen()
+	}
+
+	return seq
+}
+
+// Name
+func (osc52Backend) Name() string {
+	return BackendOSC52
+}
+
+// Available if there is a terminal to send the sequence to
+// The sequence goes to stderr, so that it does not mix with the interface that is drawn on stdout
+func (osc52Backend) Available() bool {
+	return term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// WriteSecret
+// Terminals have no way to mark the contents as sensitive
+func (osc52Backend) WriteSecret(value string) error {
+	_, err := passthrough(osc52.New(value)).WriteTo(os.Stderr)
+
+	return err
+}
+
+// Readable
+// Querying the clipboard needs the terminal to answer on stdin, which few of them allow
+func (osc52Backend) Readable() bool {
+	return false
+}
+
+// Read
+func (osc52Backend) Read() (string, error) {
+	return "", READ_UNSUPPORTED_ERR
+}
+
+// Clear
+func (osc52Backend) Clear() error {
+	_, err := passthrough(osc52.Clear()).WriteTo(os.Stderr)
+
+	return err
+}
@@ -15,6 +15,23 @@ import (
 type StatusBarMsg struct {
 	Message      string
 	ErrorMessage bool
+
+	// Optional countdown shown after the message
+	Countdown *Countdown
+}
+
+// Returns a command that shows the error in the status bar
+func StatusBarError(err error) tea.Cmd {
+	return func() tea.Msg { return StatusBarMsg{Message: err.Error(), ErrorMessage: true} }
+}
+
+// Countdown shown in the status bar, as `<label> in <seconds>s`
+type Countdown struct {
+	// What happens when it runs out
+	Label string
+
+	// When it runs out
+	Until time.Time
 }
 
 type StatusBar struct {
@@ -24,6 +41,9 @@ type StatusBar struct {
 	// Is the meessage a error message
 	ErrorMessage bool
 
+	// Countdown, if any
+	Countdown *Countdown
+
 	// Current user
 	CurrentUser string
 }
@@ -40,6 +60,7 @@ func (bar *StatusBar) Update(msg tea.Msg) tea.Cmd {
 	case StatusBarMsg:
 		bar.Message = msgType.Message
 		bar.ErrorMessage = msgType.ErrorMessage
+		bar.Countdown = msgType.Countdown
 	}
 
 	return nil
@@ -82,6 +103,16 @@ func (bar *StatusBar) View() string {
 		render_fn = tlockstyles.Styles.Error.Render
 	}
 
+	// Message
+	message := bar.Message
+
+	// Add countdown until it runs out
+	if bar.Countdown != nil {
+		if remaining := time.Until(bar.Countdown.Until).Round(time.Second); remaining > 0 {
+			message += fmt.Sprintf(" (%s in %ds)", bar.Countdown.Label, int(remaining.Seconds()))
+		}
+	}
+
 	// Return
-	return lipgloss.JoinVertical(lipgloss.Left, ui, render_fn("› "+bar.Message))
+	return lipgloss.JoinVertical(lipgloss.Left, ui, render_fn("› "+message))
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	_ "embed"
 
@@ -38,6 +39,32 @@ func (key *Keybinding) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// Duration written as `30s`, `5m`, `1h` or just `0`
+type Duration struct {
+	time.Duration
+}
+
+// Custom unmarshaller
+func (duration *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw string
+
+	// Check for errors
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	// Parse
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return err
+	}
+
+	duration.Duration = parsed
+
+	// Return
+	return nil
+}
+
 // Quick utility to create key binding
 func new_key(keys ...string) Keybinding {
 	return Keybinding{Binding: bubblekey.NewBinding(bubblekey.WithKeys(keys...))}
@@ -48,6 +75,22 @@ type UserConfiguration struct {
 	// Whether to enable icons
 	EnableIcons bool `yaml:"enable_icons"`
 
+	// Locks the vault after being idle for this long, 0 disables it
+	AutoLock Duration `yaml:"auto_lock"`
+
+	// Clears the copied code from the clipboard after this long, 0 disables it
+	// Defaults to the period of the token when not set
+	ClipboardClear *Duration `yaml:"clipboard_clear"`
+
+	// Clipboard
+	Clipboard ClipboardConfig `yaml:"clipboard"`
+
+	// Screen capture, for adding tokens from the screen
+	ScreenCapture ScreenCaptureConfig `yaml:"screen_capture"`
+
+	// Number of counters searched ahead of the current one when resynchronising a HOTP token
+	HOTPLookahead int `yaml:"hotp_lookahead"`
+
 	// Folder keybindings
 	Folder FolderKeyBinds `yaml:"folders_keybindings"`
 
@@ -55,6 +98,28 @@ type UserConfiguration struct {
 	Tokens TokenKeyBinds `yaml:"tokens_keybindings"`
 }
 
+// Clipboard config
+type ClipboardConfig struct {
+	// Backend to use: auto, native, osc52 or command
+	Backend string `yaml:"backend"`
+
+	// Command that copies its stdin, for the command backend
+	CopyCommand []string `yaml:"copy_command"`
+
+	// Command that prints the clipboard, for the command backend
+	PasteCommand []string `yaml:"paste_command"`
+}
+
+// Screen capture config
+type ScreenCaptureConfig struct {
+	// Whether to select the region to scan with slurp, on wayland
+	SelectRegion bool `yaml:"select_region"`
+
+	// Command that captures the screen, instead of the built in capture
+	// `{file}` and `{region}` in its arguments are replaced by the output file and the region selected with slurp
+	Command []string `yaml:"command"`
+}
+
 // Folder keybinds
 type FolderKeyBinds struct {
 	// Add folder
@@ -77,6 +142,9 @@ type FolderKeyBinds struct {
 
 	// Delete
 	Delete Keybinding `yaml:"delete"`
+
+	// Import tokens from another app
+	Import Keybinding `yaml:"import"`
 }
 
 // Tokens keybinds
@@ -87,6 +155,9 @@ type TokenKeyBinds struct {
 	// Add token from screen
 	AddScreen Keybinding `yaml:"add_from_screen"`
 
+	// Add tokens from image file
+	AddFile Keybinding `yaml:"add_from_file"`
+
 	// Edit token
 	Edit Keybinding `yaml:"edit"`
 
@@ -113,14 +184,23 @@ type TokenKeyBinds struct {
 
 	// Next token for HOTP
 	Move Keybinding `yaml:"move"`
+
+	// Show as QR code
+	ShowQR Keybinding `yaml:"show_qr"`
+
+	// Resynchronise the counter of HOTP
+	ResyncHOTP Keybinding `yaml:"resync_hotp"`
 }
 
 // Returns the default keybindings
 func DefaultUserConfiguration() UserConfiguration {
 	return UserConfiguration{
-		EnableIcons: false,
-		Folder:      DefaultFolderKeyBinds(),
-		Tokens:      DefaultTokensKeyBinds(),
+		EnableIcons:   false,
+		AutoLock:      Duration{5 * time.Minute},
+		Clipboard:     ClipboardConfig{Backend: "auto"},
+		HOTPLookahead: 100,
+		Folder:        DefaultFolderKeyBinds(),
+		Tokens:        DefaultTokensKeyBinds(),
 	}
 }
 
@@ -134,23 +214,27 @@ func DefaultFolderKeyBinds() FolderKeyBinds {
 		MoveUp:   new_key("ctrl+up"),
 		MoveDown: new_key("ctrl+down"),
 		Delete:   new_key("D"),
+		Import:   new_key("I"),
 	}
 }
 
 // Default tokens keybindings
 func DefaultTokensKeyBinds() TokenKeyBinds {
 	return TokenKeyBinds{
-		Add:       new_key("a"),
-		Edit:      new_key("e"),
-		Next:      new_key("j"),
-		Previous:  new_key("k"),
-		MoveUp:    new_key("J"),
-		MoveDown:  new_key("K"),
-		Delete:    new_key("d"),
-		AddScreen: new_key("s"),
-		Copy:      new_key("c"),
-		Move:      new_key("m"),
-		NextHOTP:  new_key("n"),
+		Add:        new_key("a"),
+		Edit:       new_key("e"),
+		Next:       new_key("j"),
+		Previous:   new_key("k"),
+		MoveUp:     new_key("J"),
+		MoveDown:   new_key("K"),
+		Delete:     new_key("d"),
+		AddScreen:  new_key("s"),
+		AddFile:    new_key("f"),
+		Copy:       new_key("c"),
+		Move:       new_key("m"),
+		NextHOTP:   new_key("n"),
+		ShowQR:     new_key("q"),
+		ResyncHOTP: new_key("R"),
 	}
 }
 
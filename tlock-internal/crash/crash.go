@@ -0,0 +1,75 @@
+package crash
+
+import (
+	"reflect"
+	"runtime/debug"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Runs on a recovered panic, it is expected to exit the process
+var handler func(reason any, stack []byte)
+
+// Only the first panic is handled, the others wait for it to exit the process
+var handled sync.Once
+
+// Sets what runs on a recovered panic, like restoring the terminal and flushing the vaults
+func SetHandler(onPanic func(reason any, stack []byte)) {
+	handler = onPanic
+}
+
+// Recovers from a panic in the calling goroutine and hands it to the handler
+// It must be deferred directly, as in `defer crash.Recover()`
+// Bubbletea does not recover the panics of the commands, as they run in their own goroutines
+func Recover() {
+	reason := recover()
+
+	if reason == nil {
+		return
+	}
+
+	// Nothing to hand it to
+	if handler == nil {
+		panic(reason)
+	}
+
+	stack := debug.Stack()
+
+	handled.Do(func() { handler(reason, stack) })
+}
+
+// Type of the commands, to find the batched and sequenced ones
+var cmdType = reflect.TypeOf(tea.Cmd(nil))
+
+// Wraps the command so that its panics are recovered
+// The commands that it batches or sequences are wrapped as well, as bubbletea runs them in their own goroutines
+func Cmd(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		defer Recover()
+
+		return wrapNested(cmd())
+	}
+}
+
+// Wraps the commands inside of a batch or sequence message
+// Sequences have an unexported type, but both are slices of commands
+func wrapNested(msg tea.Msg) tea.Msg {
+	value := reflect.ValueOf(msg)
+
+	if value.Kind() != reflect.Slice || value.Type().Elem() != cmdType {
+		return msg
+	}
+
+	wrapped := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+
+	for i := 0; i < value.Len(); i++ {
+		wrapped.Index(i).Set(reflect.ValueOf(Cmd(value.Index(i).Interface().(tea.Cmd))))
+	}
+
+	return wrapped.Interface()
+}
@@ -9,8 +9,8 @@ import (
 	tlockvault "github.com/eklairs/tlock/tlock-vault"
 )
 
-// Validator
-type Validator = func(vault *tlockvault.Vault, value string) error
+// Validator, it is given the values of all the items of the form along with the value of its item
+type Validator = func(vault *tlockvault.Vault, value string, values map[string]string) error
 
 // Message that represents that the form is submitted and the items pass the validators
 type FormSubmittedMsg struct {
@@ -76,6 +76,18 @@ func (form *Form) AddOption(id, title, desc string, options []string) {
 	})
 }
 
+// Selects the value of an option, unknown values are ignored
+func (form *Form) SelectOption(id, value string) {
+	// Find the index
+	index := slices.IndexFunc(form.Items, func(item FormItemWrapped) bool { return item.ID == id })
+
+	if option, ok := form.Items[index].FormItem.(*FormItemOptionBox); ok {
+		if selected := slices.Index(option.Values, value); selected != -1 {
+			option.SelectedIndex = selected
+		}
+	}
+}
+
 // Switches focus from one index to another
 func (form *Form) switchFocus(old, new int) {
 	// Do focus changing
@@ -95,33 +107,33 @@ func (form *Form) Update(msg tea.Msg, vault *tlockvault.Vault) tea.Cmd {
 	match_key:
 		switch msgType.String() {
 		case "tab":
-			if form.FocusedIndex != len(form.Items)-1 {
-				next := form.FocusedIndex + 1
+			// Skip the disabled items
+			next := form.FocusedIndex + 1
 
-				// If the next is disabled, then switch to its next
-				if !form.Items[next].Enabled {
-					next += 1
-				}
+			for next < len(form.Items) && !form.Items[next].Enabled {
+				next += 1
+			}
 
-				// Change focus
+			// Change focus
+			if next < len(form.Items) {
 				form.switchFocus(form.FocusedIndex, next)
 			}
 		case "shift+tab":
-			if form.FocusedIndex != 0 {
-				next := form.FocusedIndex - 1
+			// Skip the disabled items
+			next := form.FocusedIndex - 1
 
-				// If the next is disabled, then switch to its next
-				if !form.Items[next].Enabled && 0 != next-1 {
-					next -= 1
-				}
+			for next >= 0 && !form.Items[next].Enabled {
+				next -= 1
+			}
 
-				// Change focus
+			// Change focus
+			if next >= 0 {
 				form.switchFocus(form.FocusedIndex, next)
 			}
 		case "enter":
 			data := make(map[string]string)
 
-			// Validate them all!
+			// Collect the values first, validators may depend on the other items
 			for _, item := range form.Items {
 				// Get the value
 				value := strings.TrimSpace(item.FormItem.Value())
@@ -131,13 +143,19 @@ func (form *Form) Update(msg tea.Msg, vault *tlockvault.Vault) tea.Cmd {
 					value = defaultValue
 				}
 
+				// Set the item
+				data[item.ID] = value
+			}
+
+			// Validate them all!
+			for _, item := range form.Items {
 				// Remove current error
 				item.FormItem.SetError(nil)
 
 				// Run validators
 				for _, validator := range item.Validators {
 					// Validate
-					if err := validator(vault, value); err != nil {
+					if err := validator(vault, data[item.ID], data); err != nil {
 						// Set erro
 						item.FormItem.SetError(&err)
 
@@ -145,9 +163,6 @@ func (form *Form) Update(msg tea.Msg, vault *tlockvault.Vault) tea.Cmd {
 						break match_key
 					}
 				}
-
-				// Set the item
-				data[item.ID] = value
 			}
 
 			// Return
@@ -93,12 +93,15 @@ func (item FormItemOptionBox) View() string {
 		renderables[index] += " "
 	}
 
+	options := lipgloss.JoinHorizontal(lipgloss.Left, renderables...)
+
 	ui := lipgloss.JoinVertical(
 		lipgloss.Left,
 		tlockstyles.Styles.Title.Render(item.Title),
 		tlockstyles.Styles.SubText.Render(item.Description), "",
-		lipgloss.JoinHorizontal(lipgloss.Left, renderables...),
+		options,
 	)
 
-	return lipgloss.NewStyle().Width(31).Render(ui)
+	// Wide enough to keep the options on a single line
+	return lipgloss.NewStyle().Width(max(31, lipgloss.Width(options))).Render(ui)
 }
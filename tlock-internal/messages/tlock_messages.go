@@ -38,3 +38,46 @@ type UserDeletedMsg struct{}
 type UserEditedMsg struct {
 	NewName string
 }
+
+// Writing the vault to the disk failed
+type VaultWriteFailedMsg struct {
+	// The error
+	Err error
+
+	// Vault that failed to write
+	Vault *tlockvault.Vault
+}
+
+// Waits for the next write error of the vault, until the vault is closed
+func ListenVaultWriteErrors(vault *tlockvault.Vault) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case err := <-vault.WriteErrors():
+			return VaultWriteFailedMsg{Err: err, Vault: vault}
+
+		case <-vault.Closed():
+			return nil
+		}
+	}
+}
+
+// The dashboard has been opened with an unlocked vault
+type VaultUnlockedMsg struct{}
+
+// Asks the dashboard to lock the vault, as the user has been idle
+type LockVaultMsg struct{}
+
+// The dashboard has locked the vault
+type VaultLockedMsg struct {
+	// User whose vault was locked
+	Username string
+
+	// Name of the folder that was focused
+	Folder string
+
+	// ID of the token that was focused
+	Token string
+
+	// Error while saving the pending changes, if any
+	Err error
+}
@@ -6,6 +6,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/eklairs/tlock/tlock-internal/components"
+	tlockmessages "github.com/eklairs/tlock/tlock-internal/messages"
 	"golang.org/x/term"
 )
 
@@ -25,6 +26,7 @@ const (
 	OperationPush
 	OperationPop
 	OperationReplace
+	OperationReset
 )
 
 // Type of operation
@@ -34,6 +36,9 @@ type Operation struct {
 
 	// Related screen
 	Screen *Screen
+
+	// New stack, for reset
+	Screens []Screen
 }
 
 // None operation
@@ -81,6 +86,22 @@ func (manager *ModelManager) ReplaceScreen(screen Screen) tea.Cmd {
 	return screen.Init()
 }
 
+// Replaces the whole stack with the given screens, the last one being at the top
+func (manager *ModelManager) ResetScreens(screens ...Screen) tea.Cmd {
+	manager.operation = Operation{
+		Action:  OperationReset,
+		Screens: screens,
+	}
+
+	cmds := make([]tea.Cmd, len(screens))
+
+	for i, screen := range screens {
+		cmds[i] = screen.Init()
+	}
+
+	return tea.Batch(cmds...)
+}
+
 // Pops the top screen from the stack
 func (manager *ModelManager) PopScreen() {
 	if len(manager.stack) > 1 {
@@ -100,11 +121,15 @@ func (manager *ModelManager) Update(msg tea.Msg) tea.Cmd {
 
 	// Update
 	switch msg.(type) {
-	case components.StatusBarMsg:
-		// Send it to all the screens
+	case components.StatusBarMsg, tlockmessages.LockVaultMsg:
+		// Send it to all the screens, keeping the commands of each of them
+		cmds := make([]tea.Cmd, len(manager.stack))
+
 		for i := 0; i < len(manager.stack); i++ {
-			manager.stack[i], cmd = manager.stack[i].Update(msg, manager)
+			manager.stack[i], cmds[i] = manager.stack[i].Update(msg, manager)
 		}
+
+		cmd = tea.Batch(cmds...)
 	default:
 		manager.stack[screen_index], cmd = manager.stack[screen_index].Update(msg, manager)
 	}
@@ -132,6 +157,9 @@ func (manager *ModelManager) ResolveOperation() {
 
 	case OperationReplace:
 		manager.stack[screen_index] = *manager.operation.Screen
+
+	case OperationReset:
+		manager.stack = manager.operation.Screens
 	}
 
 	// Reset operation
@@ -18,6 +18,9 @@ var VAULT_DIR = path.Join(DATA_BASE, "vaults")
 // Path to the list of users
 var USERS = path.Join(DATA_BASE, "users.bin")
 
+// Directory that contains the sockets of the unlock agents
+var AGENT_DIR = path.Join(xdg.RuntimeDir, "tlock")
+
 // Path to tlock internal config file
 var TLOCK_CONFIG = path.Join(CONFIG_BASE, "config_internal_ignore.bin")
 
@@ -25,3 +28,8 @@ var TLOCK_CONFIG = path.Join(CONFIG_BASE, "config_internal_ignore.bin")
 func UserConfigFor(username string) string {
 	return path.Join(CONFIG_BASE, username, "config.yaml")
 }
+
+// Returns the path to the socket of the given user's unlock agent
+func AgentSocketFor(username string) string {
+	return path.Join(AGENT_DIR, username+".sock")
+}
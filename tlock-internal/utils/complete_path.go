@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Returns the paths that the partially typed path can be completed to
+// Only directories and files with one of the extensions are included, directories end with a separator
+func CompletePath(partial string, extensions []string) []string {
+	// Directory to list, as typed so that the completions start with the typed value
+	typedDir := partial[:strings.LastIndexAny(partial, `/\`)+1]
+
+	dir := ExpandHome(typedDir)
+
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	completions := make([]string, 0)
+	prefix := partial[len(typedDir):]
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		// Hidden files, unless asked for
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		// Follow symlinks
+		isDir := entry.IsDir()
+
+		if entry.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+
+		switch {
+		case isDir:
+			completions = append(completions, typedDir+name+string(filepath.Separator))
+		case slices.Contains(extensions, strings.ToLower(filepath.Ext(name))):
+			completions = append(completions, typedDir+name)
+		}
+	}
+
+	return completions
+}
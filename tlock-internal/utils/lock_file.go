@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Error when the file is already locked by another process
+var FILE_LOCKED_ERR = errors.New("File is locked by another process")
+
+// Takes an exclusive advisory lock on the file, creating it if required
+// The lock is held till the returned file is closed or the process exits
+// It returns FILE_LOCKED_ERR if another process holds the lock
+func LockFile(file string) (*os.File, error) {
+	// Ensure that the parent of the file exists
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return nil, err
+	}
+
+	// Open
+	lockFile, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lock
+	if err := tryLock(lockFile); err != nil {
+		lockFile.Close()
+
+		return nil, err
+	}
+
+	return lockFile, nil
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || openbsd || netbsd || dragonfly || windows)
+
+package utils
+
+import "os"
+
+// File locking is not supported on this platform, every lock succeeds
+func tryLock(file *os.File) error {
+	return nil
+}
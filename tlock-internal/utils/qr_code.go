@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// Modules of white border around the QR code, most scanners need some
+const qrCodeMargin = 2
+
+// Pixels per module of the PNG images
+const qrCodePNGScale = 8
+
+// Encodes the content as a QR code, with one bit per module
+func encodeQRCode(content string) (*gozxing.BitMatrix, error) {
+	hints := map[gozxing.EncodeHintType]interface{}{
+		gozxing.EncodeHintType_MARGIN:           qrCodeMargin,
+		gozxing.EncodeHintType_ERROR_CORRECTION: "M",
+	}
+
+	// Zero size gives the smallest matrix, without any scaling
+	return qrcode.NewQRCodeWriter().Encode(content, gozxing.BarcodeFormat_QR_CODE, 0, 0, hints)
+}
+
+// Renders the content as a QR code with unicode half blocks, each character holds two rows of modules
+// Dark modules are drawn with the foreground color, so it should be rendered dark on a light background
+func RenderQRCode(content string) (string, error) {
+	matrix, err := encodeQRCode(content)
+	if err != nil {
+		return "", err
+	}
+
+	width, height := matrix.GetWidth(), matrix.GetHeight()
+	lines := make([]string, 0, (height+1)/2)
+
+	for y := 0; y < height; y += 2 {
+		var line strings.Builder
+
+		for x := 0; x < width; x++ {
+			top := matrix.Get(x, y)
+			bottom := y+1 < height && matrix.Get(x, y+1)
+
+			switch {
+			case top && bottom:
+				line.WriteString("█")
+			case top:
+				line.WriteString("▀")
+			case bottom:
+				line.WriteString("▄")
+			default:
+				line.WriteString(" ")
+			}
+		}
+
+		lines = append(lines, line.String())
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// Encodes the content as a QR code in a PNG image
+func QRCodePNG(content string) ([]byte, error) {
+	matrix, err := encodeQRCode(content)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := matrix.GetWidth(), matrix.GetHeight()
+	img := image.NewGray(image.Rect(0, 0, width*qrCodePNGScale, height*qrCodePNGScale))
+
+	for y := 0; y < height*qrCodePNGScale; y++ {
+		for x := 0; x < width*qrCodePNGScale; x++ {
+			pixel := color.Gray{Y: 0xff}
+
+			if matrix.Get(x/qrCodePNGScale, y/qrCodePNGScale) {
+				pixel = color.Gray{Y: 0}
+			}
+
+			img.SetGray(x, y, pixel)
+		}
+	}
+
+	var buffer bytes.Buffer
+
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -1,16 +1,23 @@
 package utils
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"image"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	_ "image/gif"
+	_ "image/jpeg"
 	_ "image/png"
 
 	"github.com/kbinani/screenshot"
 	"github.com/makiuchi-d/gozxing"
+	multiqrcode "github.com/makiuchi-d/gozxing/multi/qrcode"
 	"github.com/makiuchi-d/gozxing/qrcode"
 )
 
@@ -33,7 +40,7 @@ func getImageFromFilePath(filePath string) (image.Image, error) {
 }
 
 // Returns if the current session is wayland
-func isWayland() bool {
+func IsWayland() bool {
 	return os.Getenv("XDG_SESSION_TYPE") == "wayland"
 }
 
@@ -62,49 +69,258 @@ func readFromImage(image image.Image) (*string, error) {
 
 }
 
+// Reads all the QRCodes from the image
+func readAllFromImage(image image.Image) ([]string, error) {
+	// Create bitmap
+	bmp, err := gozxing.NewBinaryBitmapFromImage(image)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode
+	results, _ := multiqrcode.NewQRCodeMultiReader().DecodeMultiple(bmp, nil)
+
+	uris := make([]string, 0, len(results))
+
+	for _, result := range results {
+		uris = appendUnique(uris, result.String())
+	}
+
+	// The multi reader misses some codes that the single one finds, like ones filling the whole image
+	if len(uris) == 0 {
+		if uri, err := readFromImage(image); err == nil {
+			uris = append(uris, *uri)
+		}
+	}
+
+	return uris, nil
+}
+
 // Error when no token on the screen is found
 var TOKEN_NOT_FOUND_ERR = errors.New("No token found on the screen")
 
-// Reads QRCode from screen and returns the found data (not for wayland)
-func ReadTokenFromScreenNoWayland() (*string, error) {
-	// Capture rect
-	image, err := screenshot.CaptureRect(screenshot.GetDisplayBounds(0))
+// Error when no token in the image is found
+var TOKEN_NOT_FOUND_IN_IMAGE_ERR = errors.New("No QR code found in the image")
+
+// Error when the file is not an image that can be read
+var IMAGE_UNSUPPORTED_ERR = errors.New("Cannot read the image, only PNG, JPEG and GIF images are supported")
+
+// Extensions of the images the QRCodes can be read from
+var ImageExtensions = []string{".png", ".jpg", ".jpeg", ".gif"}
+
+// Reads all the QRCodes from a PNG, JPEG or GIF image file
+func ReadTokensFromImageFile(path string) ([]string, error) {
+	// Load image
+	image, err := getImageFromFilePath(path)
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, IMAGE_UNSUPPORTED_ERR
+	}
+
+	// Read
+	uris, err := readAllFromImage(image)
 	if err != nil {
 		return nil, err
 	}
 
-	// Return
-	return readFromImage(image)
+	if len(uris) == 0 {
+		return nil, TOKEN_NOT_FOUND_IN_IMAGE_ERR
+	}
+
+	return uris, nil
+}
+
+// Appends the values that are not in the list yet
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		if !slices.Contains(list, value) {
+			list = append(list, value)
+		}
+	}
+
+	return list
+}
+
+// Reads the QRCodes from every active display and returns the found data (not for wayland)
+// Mirrored displays show the same QRCodes, they are only returned once
+func ReadTokensFromScreenNoWayland() ([]string, error) {
+	uris := make([]string, 0)
+
+	// First error, only returned if no display could be captured
+	var captureErr error
+
+	for display := 0; display < screenshot.NumActiveDisplays(); display++ {
+		// Capture display
+		image, err := screenshot.CaptureDisplay(display)
+		if err != nil {
+			if captureErr == nil {
+				captureErr = err
+			}
+
+			continue
+		}
+
+		// Read
+		found, err := readAllFromImage(image)
+		if err != nil {
+			return nil, err
+		}
+
+		uris = appendUnique(uris, found...)
+	}
+
+	if len(uris) == 0 && captureErr != nil {
+		return nil, captureErr
+	}
+
+	if len(uris) == 0 {
+		return nil, TOKEN_NOT_FOUND_ERR
+	}
+
+	return uris, nil
 }
 
-// Reads QRCode form screen and returns the found data (only for wayland)
-func ReadTokenFromScreenWaylandOnly() (*string, error) {
-	// Out path
-	out := "/tmp/tlock_screenshot.png"
+// Placeholders in the arguments of the capture command
+const (
+	// Replaced by the file the command writes the image to
+	captureFilePlaceholder = "{file}"
+
+	// Replaced by the region selected with slurp
+	captureRegionPlaceholder = "{region}"
+)
+
+// Error when the region selection was cancelled
+var REGION_NOT_SELECTED_ERR = errors.New("No region was selected")
+
+// Lets the user select a region of the screen with slurp, and returns its geometry
+func selectRegion() (string, error) {
+	output, err := exec.Command("slurp").Output()
+
+	// slurp exits with an error when the selection is cancelled
+	var exitErr *exec.ExitError
+
+	if errors.As(err, &exitErr) {
+		return "", REGION_NOT_SELECTED_ERR
+	}
 
-	// Make grim command
-	_, err := exec.Command("grim", out).Output()
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Cannot run grim command: %s", err))
+		return "", fmt.Errorf("Cannot run slurp command: %w", err)
+	}
+
+	region := strings.TrimSpace(string(output))
+
+	if region == "" {
+		return "", REGION_NOT_SELECTED_ERR
 	}
 
-	// Load screenshot
-	image, err := getImageFromFilePath(out)
+	return region, nil
+}
+
+// Runs the capture command and loads the image it captured
+// The image is read from the stdout of the command, or from the file if `{file}` is in its arguments
+// That file is created in a private temporary directory, which is removed right after the image is loaded
+func captureWithCommand(command []string) (image.Image, error) {
+	args := slices.Clone(command)
+
+	// Region
+	if slices.ContainsFunc(args, func(arg string) bool { return strings.Contains(arg, captureRegionPlaceholder) }) {
+		region, err := selectRegion()
+		if err != nil {
+			return nil, err
+		}
+
+		for index := range args {
+			args[index] = strings.ReplaceAll(args[index], captureRegionPlaceholder, region)
+		}
+	}
+
+	// Output file
+	var file string
+
+	if slices.ContainsFunc(args, func(arg string) bool { return strings.Contains(arg, captureFilePlaceholder) }) {
+		// Only we can read the directory
+		dir, err := os.MkdirTemp("", "tlock-capture-*")
+		if err != nil {
+			return nil, err
+		}
+
+		// The capture can have anything that was on the screen
+		defer os.RemoveAll(dir)
+
+		file = filepath.Join(dir, "capture.png")
+
+		for index := range args {
+			args[index] = strings.ReplaceAll(args[index], captureFilePlaceholder, file)
+		}
+	}
+
+	// Capture
+	output, err := exec.Command(args[0], args[1:]...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot run %s command: %w", args[0], err)
+	}
+
+	// Load
+	if file != "" {
+		return getImageFromFilePath(file)
+	}
+
+	image, _, err := image.Decode(bytes.NewReader(output))
+	if err != nil {
+		return nil, IMAGE_UNSUPPORTED_ERR
+	}
+
+	return image, nil
+}
+
+// Reads the QRCodes from the image captured by the command
+func ReadTokensFromScreenCommand(command []string) ([]string, error) {
+	image, err := captureWithCommand(command)
 	if err != nil {
 		return nil, err
 	}
 
 	// Read
-	return readFromImage(image)
+	uris, err := readAllFromImage(image)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(uris) == 0 {
+		return nil, TOKEN_NOT_FOUND_ERR
+	}
+
+	return uris, nil
 }
 
-// Reads QRCode from screen based on the session type
-func ReadTokenFromScreen() (*string, error) {
+// Reads the QRCodes from the screen and returns the found data (only for wayland)
+// grim captures all the outputs at once, or only the region selected with slurp
+// The capture is streamed through its stdout, so it never touches the disk
+func ReadTokensFromScreenWaylandOnly(region bool) ([]string, error) {
+	command := []string{"grim", "-"}
+
+	if region {
+		command = []string{"grim", "-g", captureRegionPlaceholder, "-"}
+	}
+
+	return ReadTokensFromScreenCommand(command)
+}
+
+// Reads the QRCodes from the screen with the capture command if there is one, otherwise based on the session type
+func ReadTokensFromScreen(command []string, region bool) ([]string, error) {
+	// Capture command of the user
+	if len(command) != 0 {
+		return ReadTokensFromScreenCommand(command)
+	}
+
 	// Check if it is wayland
-	if isWayland() {
-		return ReadTokenFromScreenWaylandOnly()
+	if IsWayland() {
+		return ReadTokensFromScreenWaylandOnly(region)
 	}
 
 	// Use normal function
-	return ReadTokenFromScreenNoWayland()
+	return ReadTokensFromScreenNoWayland()
 }
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Atomically replaces the file with the given data
+// The data is written to a temporary file in the same directory, synced to the disk and then renamed over the file,
+// so a crash in between leaves either the old or the new file, never a partially written one
+func WriteFileAtomic(file string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(file)
+
+	// Ensure that the parent of the file exists
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	// Create temp file next to the file, so that the rename does not cross filesystems
+	temp, err := os.CreateTemp(dir, "."+filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	// Remove the temp file if anything fails before the rename
+	tempPath := temp.Name()
+	renamed := false
+
+	defer func() {
+		if !renamed {
+			temp.Close()
+			os.Remove(tempPath)
+		}
+	}()
+
+	// Restrict permissions
+	if err := temp.Chmod(perm); err != nil {
+		return err
+	}
+
+	// Write
+	if _, err := temp.Write(data); err != nil {
+		return err
+	}
+
+	// Flush to the disk
+	if err := temp.Sync(); err != nil {
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	// Replace
+	if err := os.Rename(tempPath, file); err != nil {
+		return err
+	}
+
+	renamed = true
+
+	// Sync the directory so that the rename itself is durable
+	// Not every platform supports syncing directories, so this is best effort
+	if dirFile, err := os.Open(dir); err == nil {
+		dirFile.Sync()
+		dirFile.Close()
+	}
+
+	return nil
+}
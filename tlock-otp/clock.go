@@ -0,0 +1,24 @@
+package tlockotp
+
+import "time"
+
+// Source of the current time, codes are generated for the time it returns
+type Clock interface {
+	Now() time.Time
+}
+
+// Clock that reads the time of the system
+type SystemClock struct{}
+
+// Now()
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Clock that is stuck at the given time, to generate the codes of any moment
+type FixedClock time.Time
+
+// Now()
+func (clock FixedClock) Now() time.Time {
+	return time.Time(clock)
+}
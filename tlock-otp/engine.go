@@ -0,0 +1,98 @@
+package tlockotp
+
+import (
+	"errors"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Error representing that the code asked for is before the first one
+var ERR_NO_PREVIOUS_CODE = errors.New("There is no code before the first one")
+
+// Period of the time based tokens that have none
+const defaultPeriod = 30
+
+// Generates the codes of the tokens at the time of its clock
+type Engine struct {
+	clock Clock
+}
+
+// Initializes an engine that reads the time from the given clock
+func New(clock Clock) Engine {
+	return Engine{clock: clock}
+}
+
+// Engine that reads the time of the system
+var System = New(SystemClock{})
+
+// Period of a time based token
+func period(token tlockvault.Token) int64 {
+	if token.Period <= 0 {
+		return defaultPeriod
+	}
+
+	return int64(token.Period)
+}
+
+// Returns the current step of the token, its counter if it is HOTP and its time step otherwise
+func (engine Engine) Step(token tlockvault.Token) uint64 {
+	if !token.Type.TimeBased() {
+		return uint64(token.Counter())
+	}
+
+	return uint64(engine.clock.Now().Unix() / period(token))
+}
+
+// Returns the code of the token at the given step
+func (engine Engine) CodeAt(token tlockvault.Token, step uint64) (string, error) {
+	generator, err := GeneratorFor(token.Type)
+	if err != nil {
+		return "", err
+	}
+
+	return generator.Generate(token, step)
+}
+
+// Returns the current code of the token
+// HOTP tokens return the code for their current counter, without increasing it
+func (engine Engine) Code(token tlockvault.Token) (string, error) {
+	return engine.CodeAt(token, engine.Step(token))
+}
+
+// Returns the code after the current one, of the next period or of the next counter
+func (engine Engine) NextCode(token tlockvault.Token) (string, error) {
+	return engine.CodeAt(token, engine.Step(token)+1)
+}
+
+// Returns the code before the current one, of the previous period or of the previous counter
+func (engine Engine) PreviousCode(token tlockvault.Token) (string, error) {
+	step := engine.Step(token)
+
+	if step == 0 {
+		return "", ERR_NO_PREVIOUS_CODE
+	}
+
+	return engine.CodeAt(token, step-1)
+}
+
+// Returns the seconds remaining before the code of a time based token changes, HOTP tokens have none
+func (engine Engine) RemainingTime(token tlockvault.Token) int {
+	if !token.Type.TimeBased() {
+		return 0
+	}
+
+	return int(period(token) - engine.clock.Now().Unix()%period(token))
+}
+
+// Returns the current code of the token, with the time of the system
+// Tokens that cannot generate codes, like ones with an invalid secret, have an empty code
+func CurrentCode(token tlockvault.Token) string {
+	code, _ := System.Code(token)
+
+	return code
+}
+
+// Returns the seconds remaining before the code of a time based token changes, with the time of the system
+func RemainingTime(token tlockvault.Token) int {
+	return System.RemainingTime(token)
+}
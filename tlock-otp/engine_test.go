@@ -0,0 +1,88 @@
+package tlockotp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	"github.com/pquerna/otp"
+)
+
+func TestNextAndPreviousCode(t *testing.T) {
+	// RFC 6238 Appendix B
+	totp := tlockvault.Token{
+		Type:             tlockvault.TokenTypeTOTP,
+		Secret:           rfcSecret("12345678901234567890"),
+		Period:           30,
+		Digits:           8,
+		HashingAlgorithm: otp.AlgorithmSHA1,
+	}
+
+	// RFC 4226 Appendix D, at counter 5
+	hotp := tlockvault.Token{
+		Type:             tlockvault.TokenTypeHOTP,
+		Secret:           rfcSecret("12345678901234567890"),
+		Digits:           6,
+		HashingAlgorithm: otp.AlgorithmSHA1,
+		InitialCounter:   3,
+		UsageCounter:     2,
+	}
+
+	tests := []struct {
+		name  string
+		token tlockvault.Token
+		unix  int64
+		next  bool
+		want  string
+	}{
+		{"totp", totp, 0, true, "94287082"},
+		{"totp", totp, 1111111109, true, "14050471"},
+		{"totp", totp, 1111111111, false, "07081804"},
+		{"hotp", hotp, 0, true, "287922"},
+		{"hotp", hotp, 0, false, "338314"},
+	}
+
+	for _, test := range tests {
+		engine := New(FixedClock(time.Unix(test.unix, 0)))
+
+		// Code around the current one
+		method, code := "PreviousCode", engine.PreviousCode
+
+		if test.next {
+			method, code = "NextCode", engine.NextCode
+		}
+
+		if got, err := code(test.token); err != nil || got != test.want {
+			t.Errorf("%s at %d: %s() = %s, %v, want %s", test.name, test.unix, method, got, err, test.want)
+		}
+	}
+}
+
+func TestNoPreviousCode(t *testing.T) {
+	token := tlockvault.Token{Type: tlockvault.TokenTypeHOTP, Secret: rfcSecret("12345678901234567890"), Digits: 6}
+
+	if _, err := New(SystemClock{}).PreviousCode(token); !errors.Is(err, ERR_NO_PREVIOUS_CODE) {
+		t.Errorf("got %v, want %v", err, ERR_NO_PREVIOUS_CODE)
+	}
+}
+
+func TestRemainingTime(t *testing.T) {
+	tests := []struct {
+		token tlockvault.Token
+		unix  int64
+		want  int
+	}{
+		{tlockvault.Token{Type: tlockvault.TokenTypeTOTP, Period: 30}, 65, 25},
+		{tlockvault.Token{Type: tlockvault.TokenTypeTOTP, Period: 30}, 60, 30},
+		{tlockvault.Token{Type: tlockvault.TokenTypeTOTP}, 59, 1},
+		{tlockvault.Token{Type: tlockvault.TokenTypeMOTP, Period: 10}, 65, 5},
+		{tlockvault.Token{Type: tlockvault.TokenTypeHOTP}, 65, 0},
+	}
+
+	for _, test := range tests {
+		if got := New(FixedClock(time.Unix(test.unix, 0))).RemainingTime(test.token); got != test.want {
+			t.Errorf("%s at %d: got %d, want %d", test.token.Type, test.unix, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package tlockotp
+
+import (
+	"encoding/base32"
+	"errors"
+	"strings"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Error representing that there is no generator for the type of the token
+var ERR_TYPE_UNSUPPORTED = errors.New("Codes cannot be generated for this type of token")
+
+// Error representing that the secret of the token is not in its encoding, hex for mOTP tokens and base32 for the others
+var ERR_SECRET_INVALID = errors.New("Secret is invalid, it should be base32, or hex for mOTP tokens")
+
+// Generates the codes of one type of token
+// The step is the counter of HOTP tokens, and the number of periods since the unix epoch for the time based ones
+type Generator interface {
+	Generate(token tlockvault.Token, step uint64) (string, error)
+}
+
+// Generators by the type of the token
+var generators = map[tlockvault.TokenType]Generator{
+	tlockvault.TokenTypeTOTP:   hotpGenerator{},
+	tlockvault.TokenTypeHOTP:   hotpGenerator{},
+	tlockvault.TokenTypeSteam:  steamGenerator{},
+	tlockvault.TokenTypeYandex: yandexGenerator{},
+	tlockvault.TokenTypeMOTP:   motpGenerator{},
+}
+
+// Returns the generator of the codes of the token type
+func GeneratorFor(tokenType tlockvault.TokenType) (Generator, error) {
+	generator, ok := generators[tokenType]
+	if !ok {
+		return nil, ERR_TYPE_UNSUPPORTED
+	}
+
+	return generator, nil
+}
+
+// Decodes a base32 secret, which might be lower case or miss its padding
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+
+	if n := len(secret) % 8; n != 0 {
+		secret = secret + strings.Repeat("=", 8-n)
+	}
+
+	key, err := base32.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, ERR_SECRET_INVALID
+	}
+
+	return key, nil
+}
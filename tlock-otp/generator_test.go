@@ -0,0 +1,160 @@
+package tlockotp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	"github.com/pquerna/otp"
+)
+
+// Base32 of the ASCII secret, as used by the RFC test vectors
+func rfcSecret(ascii string) string {
+	return base32.StdEncoding.EncodeToString([]byte(ascii))
+}
+
+// Returns the code of the token at the unix time
+func codeAt(t *testing.T, token tlockvault.Token, unix int64) string {
+	t.Helper()
+
+	code, err := New(FixedClock(time.Unix(unix, 0))).Code(token)
+	if err != nil {
+		t.Fatalf("Code() returned error: %v", err)
+	}
+
+	return code
+}
+
+// RFC 4226 Appendix D
+func TestHOTP(t *testing.T) {
+	expected := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, want := range expected {
+		token := tlockvault.Token{
+			Type:             tlockvault.TokenTypeHOTP,
+			Secret:           rfcSecret("12345678901234567890"),
+			Digits:           6,
+			HashingAlgorithm: otp.AlgorithmSHA1,
+			InitialCounter:   counter,
+		}
+
+		if got := codeAt(t, token, 0); got != want {
+			t.Errorf("counter %d: got %s, want %s", counter, got, want)
+		}
+	}
+}
+
+// RFC 6238 Appendix B
+func TestTOTP(t *testing.T) {
+	secrets := map[otp.Algorithm]string{
+		otp.AlgorithmSHA1:   "12345678901234567890",
+		otp.AlgorithmSHA256: "12345678901234567890123456789012",
+		otp.AlgorithmSHA512: "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+
+	tests := []struct {
+		unix      int64
+		algorithm otp.Algorithm
+		want      string
+	}{
+		{59, otp.AlgorithmSHA1, "94287082"},
+		{59, otp.AlgorithmSHA256, "46119246"},
+		{59, otp.AlgorithmSHA512, "90693936"},
+		{1111111109, otp.AlgorithmSHA1, "07081804"},
+		{1111111109, otp.AlgorithmSHA256, "68084774"},
+		{1111111109, otp.AlgorithmSHA512, "25091201"},
+		{1111111111, otp.AlgorithmSHA1, "14050471"},
+		{1111111111, otp.AlgorithmSHA256, "67062674"},
+		{1111111111, otp.AlgorithmSHA512, "99943326"},
+		{1234567890, otp.AlgorithmSHA1, "89005924"},
+		{1234567890, otp.AlgorithmSHA256, "91819424"},
+		{1234567890, otp.AlgorithmSHA512, "93441116"},
+		{2000000000, otp.AlgorithmSHA1, "69279037"},
+		{2000000000, otp.AlgorithmSHA256, "90698825"},
+		{2000000000, otp.AlgorithmSHA512, "38618901"},
+		{20000000000, otp.AlgorithmSHA1, "65353130"},
+		{20000000000, otp.AlgorithmSHA256, "77737706"},
+		{20000000000, otp.AlgorithmSHA512, "47863826"},
+	}
+
+	for _, test := range tests {
+		token := tlockvault.Token{
+			Type:             tlockvault.TokenTypeTOTP,
+			Secret:           rfcSecret(secrets[test.algorithm]),
+			Period:           30,
+			Digits:           8,
+			HashingAlgorithm: test.algorithm,
+		}
+
+		if got := codeAt(t, token, test.unix); got != test.want {
+			t.Errorf("%s at %d: got %s, want %s", test.algorithm, test.unix, got, test.want)
+		}
+	}
+}
+
+func TestSteam(t *testing.T) {
+	token := tlockvault.Token{Type: tlockvault.TokenTypeSteam, Secret: "JBSWY3DPEHPK3PXP"}
+	token.Normalize()
+
+	if got := codeAt(t, token, 0); got != "VH8YJ" {
+		t.Errorf("got %s, want VH8YJ", got)
+	}
+}
+
+func TestYandex(t *testing.T) {
+	tests := []struct {
+		pin    string
+		secret string
+		unix   int64
+		want   string
+	}{
+		{"5239", "6SB2IKNM6OBZPAVBVTOHDKS4FAAAAAAADFUTQMBTRY", 1641559648, "umozdicq"},
+		{"7586", "LA2V6KMCGYMWWVEW64RNP3JA3IAAAAAAHTSG4HRZPI", 1581064020, "oactmacq"},
+		{"7586", "LA2V6KMCGYMWWVEW64RNP3JA3IAAAAAAHTSG4HRZPI", 1581090810, "wemdwrix"},
+		{"5210481216086702", "JBGSAU4G7IEZG6OY4UAXX62JU4AAAAAAHTSG4HXU3M", 1581091469, "dfrpywob"},
+	}
+
+	for _, test := range tests {
+		token := tlockvault.Token{Type: tlockvault.TokenTypeYandex, Secret: test.secret, PIN: test.pin}
+		token.Normalize()
+
+		if got := codeAt(t, token, test.unix); got != test.want {
+			t.Errorf("%s at %d: got %s, want %s", test.secret, test.unix, got, test.want)
+		}
+	}
+}
+
+func TestMOTP(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{165892298, "e7d8b6"},
+		{123456789, "4ebfb2"},
+	}
+
+	for _, test := range tests {
+		token := tlockvault.Token{Type: tlockvault.TokenTypeMOTP, Secret: "e3152afee62599c8", PIN: "1234"}
+		token.Normalize()
+
+		if got := codeAt(t, token, test.unix); got != test.want {
+			t.Errorf("at %d: got %s, want %s", test.unix, got, test.want)
+		}
+	}
+}
+
+func TestInvalidSecret(t *testing.T) {
+	tests := []tlockvault.Token{
+		{Type: tlockvault.TokenTypeTOTP, Secret: "not base32!"},
+		{Type: tlockvault.TokenTypeMOTP, Secret: "JBSWY3DPEHPK3PXP"},
+	}
+
+	for _, token := range tests {
+		token.Normalize()
+
+		if _, err := New(FixedClock(time.Unix(0, 0))).Code(token); err == nil {
+			t.Errorf("%s secret %q: expected an error", token.Type, token.Secret)
+		}
+	}
+}
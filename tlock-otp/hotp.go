@@ -0,0 +1,18 @@
+package tlockotp
+
+import (
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/hotp"
+)
+
+// Generates the codes of RFC 4226, and of RFC 6238 which is the same over the time step
+type hotpGenerator struct{}
+
+// Generate()
+func (hotpGenerator) Generate(token tlockvault.Token, step uint64) (string, error) {
+	return hotp.GenerateCodeCustom(token.Secret, step, hotp.ValidateOpts{
+		Digits:    otp.Digits(token.Digits),
+		Algorithm: token.HashingAlgorithm,
+	})
+}
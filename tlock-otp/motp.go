@@ -0,0 +1,25 @@
+package tlockotp
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Generates the mOTP codes
+// They are the start of the MD5 of the time step, the hex secret as it is and the PIN
+type motpGenerator struct{}
+
+// Generate()
+func (motpGenerator) Generate(token tlockvault.Token, step uint64) (string, error) {
+	// The secret is hashed as text, but it must be hex
+	if _, err := hex.DecodeString(token.Secret); err != nil {
+		return "", ERR_SECRET_INVALID
+	}
+
+	sum := md5.Sum([]byte(fmt.Sprintf("%d%s%s", step, token.Secret, token.PIN)))
+
+	return hex.EncodeToString(sum[:])[:min(token.Digits, md5.Size*2)], nil
+}
@@ -0,0 +1,76 @@
+package tlockotp
+
+import (
+	"errors"
+	"strings"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Error representing that only HOTP tokens can be resynchronised
+var ERR_RESYNC_TYPE = errors.New("Only HOTP tokens have a counter to resynchronise")
+
+// Error representing that no code was given
+var ERR_RESYNC_NO_CODE = errors.New("Enter at least one code")
+
+// Error representing that the codes are not within the lookahead window
+var ERR_RESYNC_NOT_FOUND = errors.New("The codes were not found ahead of the counter, check them or increase the lookahead")
+
+// Default number of counters searched ahead of the current one
+const DefaultLookahead = 100
+
+// Searches the counters from the current one of the HOTP token up to the lookahead for the consecutive codes
+// The codes are taken as used, so the returned counter is the one right after the last of them
+func Resync(token tlockvault.Token, codes []string, lookahead int) (int, error) {
+	if token.Type.TimeBased() {
+		return 0, ERR_RESYNC_TYPE
+	}
+
+	// Codes, without the spaces that apps put in the middle
+	wanted := make([]string, 0, len(codes))
+
+	for _, code := range codes {
+		if code = strings.ReplaceAll(strings.TrimSpace(code), " ", ""); code != "" {
+			wanted = append(wanted, code)
+		}
+	}
+
+	if len(wanted) == 0 {
+		return 0, ERR_RESYNC_NO_CODE
+	}
+
+	if lookahead <= 0 {
+		lookahead = DefaultLookahead
+	}
+
+	generator, err := GeneratorFor(token.Type)
+	if err != nil {
+		return 0, err
+	}
+
+	// Search
+	start := token.Counter()
+
+	for counter := start; counter <= start+lookahead; counter++ {
+		matched := 0
+
+		for matched < len(wanted) {
+			code, err := generator.Generate(token, uint64(counter+matched))
+			if err != nil {
+				return 0, err
+			}
+
+			if code != wanted[matched] {
+				break
+			}
+
+			matched++
+		}
+
+		if matched == len(wanted) {
+			return counter + len(wanted), nil
+		}
+	}
+
+	return 0, ERR_RESYNC_NOT_FOUND
+}
@@ -0,0 +1,55 @@
+package tlockotp
+
+import (
+	"errors"
+	"testing"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	"github.com/pquerna/otp"
+)
+
+// HOTP token of the RFC 4226 test vectors, at the given counter
+// Its codes from counter 0 are 755224, 287082, 359152, 969429, 338314, 254676, 287922, 162583, 399871, 520489
+func resyncToken(initial, usage int) tlockvault.Token {
+	return tlockvault.Token{
+		Type:             tlockvault.TokenTypeHOTP,
+		Secret:           rfcSecret("12345678901234567890"),
+		Digits:           6,
+		HashingAlgorithm: otp.AlgorithmSHA1,
+		InitialCounter:   initial,
+		UsageCounter:     usage,
+	}
+}
+
+func TestResync(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     tlockvault.Token
+		codes     []string
+		lookahead int
+		want      int
+		err       error
+	}{
+		{"code inside the window", resyncToken(0, 0), []string{"969429"}, 10, 4, nil},
+		{"code at the current counter", resyncToken(2, 1), []string{"969429"}, 10, 4, nil},
+		{"code with spaces", resyncToken(0, 0), []string{" 969 429 "}, 10, 4, nil},
+		{"code at the end of the window", resyncToken(2, 0), []string{"338314"}, 2, 5, nil},
+		{"code outside the window", resyncToken(2, 0), []string{"254676"}, 2, 0, ERR_RESYNC_NOT_FOUND},
+		{"code before the counter", resyncToken(2, 3), []string{"969429"}, 10, 0, ERR_RESYNC_NOT_FOUND},
+		{"default window", resyncToken(0, 0), []string{"520489"}, 0, 10, nil},
+		{"two consecutive codes", resyncToken(0, 0), []string{"969429", "338314"}, 10, 5, nil},
+		{"two codes that are not consecutive", resyncToken(0, 0), []string{"755224", "359152"}, 10, 0, ERR_RESYNC_NOT_FOUND},
+		{"two codes in the wrong order", resyncToken(0, 0), []string{"338314", "969429"}, 10, 0, ERR_RESYNC_NOT_FOUND},
+		{"blank codes are ignored", resyncToken(0, 0), []string{"", "969429", " "}, 10, 4, nil},
+		{"no code", resyncToken(0, 0), []string{"", " "}, 10, 0, ERR_RESYNC_NO_CODE},
+		{"time based token", tlockvault.Token{Type: tlockvault.TokenTypeTOTP}, []string{"969429"}, 10, 0, ERR_RESYNC_TYPE},
+	}
+
+	for _, test := range tests {
+		counter, err := Resync(test.token, test.codes, test.lookahead)
+
+		if !errors.Is(err, test.err) || counter != test.want {
+			t.Errorf("%s: got %d, %v, want %d, %v", test.name, counter, err, test.want, test.err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package tlockotp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Characters of the Steam Guard codes
+const steamAlphabet = "23456789BCDFGHJKMNPQRTVWXY"
+
+// Generates the Steam Guard codes
+// They come from a SHA1 TOTP, with the truncated value written in the Steam alphabet instead of decimal digits
+type steamGenerator struct{}
+
+// Generate()
+func (steamGenerator) Generate(token tlockvault.Token, step uint64) (string, error) {
+	key, err := decodeSecret(token.Secret)
+	if err != nil {
+		return "", err
+	}
+
+	// HMAC of the time step
+	mac := hmac.New(sha1.New, key)
+	binary.Write(mac, binary.BigEndian, step)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, as in RFC 4226
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+
+	// Encode, least significant character first
+	code := make([]byte, token.Digits)
+
+	for index := range code {
+		code[index] = steamAlphabet[value%uint32(len(steamAlphabet))]
+		value /= uint32(len(steamAlphabet))
+	}
+
+	return string(code), nil
+}
@@ -0,0 +1,58 @@
+package tlockotp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Characters of the Yandex.Key codes
+const yandexAlphabet = "abcdefghijklmnopqrstuvwxyz"
+
+// Size of the key at the start of the secret, the full secrets also carry a checksum
+const yandexKeySize = 16
+
+// Generates the Yandex.Key codes
+// The key of the HMAC is the SHA256 of the PIN followed by the secret, and the code is written in latin letters
+type yandexGenerator struct{}
+
+// Generate()
+func (yandexGenerator) Generate(token tlockvault.Token, step uint64) (string, error) {
+	key, err := decodeSecret(token.Secret)
+	if err != nil {
+		return "", err
+	}
+
+	if len(key) > yandexKeySize {
+		key = key[:yandexKeySize]
+	}
+
+	// Salt the secret with the PIN, a leading zero byte is dropped
+	keyHash := sha256.Sum256(append([]byte(token.PIN), key...))
+	hmacKey := keyHash[:]
+
+	if hmacKey[0] == 0 {
+		hmacKey = hmacKey[1:]
+	}
+
+	// HMAC of the time step
+	mac := hmac.New(sha256.New, hmacKey)
+	binary.Write(mac, binary.BigEndian, step)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, but to 63 bits instead of 31
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint64(sum[offset:]) & 0x7fffffffffffffff
+
+	// Encode, most significant character first
+	code := make([]byte, token.Digits)
+
+	for index := len(code) - 1; index >= 0; index-- {
+		code[index] = yandexAlphabet[value%uint64(len(yandexAlphabet))]
+		value /= uint64(len(yandexAlphabet))
+	}
+
+	return string(code), nil
+}
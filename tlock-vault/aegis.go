@@ -0,0 +1,327 @@
+package tlockvault
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pquerna/otp"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Error representing that the file is not an Aegis export
+var ERR_AEGIS_INVALID = errors.New("The file is not a valid Aegis export")
+
+// Error representing that the Aegis export is encrypted, and the password is needed
+var ERR_AEGIS_PASSWORD_REQUIRED = errors.New("The Aegis export is encrypted, its password is needed")
+
+// Error representing that the password of the Aegis export is wrong
+var ERR_AEGIS_PASSWORD_INVALID = errors.New("Wrong password for the Aegis export")
+
+// Folder for the Aegis entries that are not in any group
+const AEGIS_DEFAULT_FOLDER = "Aegis"
+
+// Type of the password slots in Aegis, the others are unlocked with biometrics
+const aegisPasswordSlot = 1
+
+// Upper bounds for the scrypt params, so that a malicious export cannot exhaust the memory
+const (
+	aegisMaxScryptN = 1 << 20
+	aegisMaxScryptR = 32
+	aegisMaxScryptP = 16
+)
+
+// Nonce and tag of an AES-GCM encrypted value in Aegis
+type aegisKeyParams struct {
+	Nonce string `json:"nonce"`
+	Tag   string `json:"tag"`
+}
+
+// Slot, which holds the master key encrypted with a key derived from the password
+type aegisSlot struct {
+	Type      int            `json:"type"`
+	Key       string         `json:"key"`
+	KeyParams aegisKeyParams `json:"key_params"`
+	N         int            `json:"n"`
+	R         int            `json:"r"`
+	P         int            `json:"p"`
+	Salt      string         `json:"salt"`
+}
+
+// Aegis export file
+type aegisFile struct {
+	Version int `json:"version"`
+	Header  struct {
+		Slots  []aegisSlot     `json:"slots"`
+		Params *aegisKeyParams `json:"params"`
+	} `json:"header"`
+
+	// Object for plain exports, base64 string for encrypted ones
+	DB json.RawMessage `json:"db"`
+}
+
+// Database of the Aegis export
+type aegisDB struct {
+	Version int          `json:"version"`
+	Entries []aegisEntry `json:"entries"`
+	Groups  []struct {
+		UUID string `json:"uuid"`
+		Name string `json:"name"`
+	} `json:"groups"`
+}
+
+// Entry of the database
+type aegisEntry struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Issuer string `json:"issuer"`
+	Info   struct {
+		Secret  string `json:"secret"`
+		Algo    string `json:"algo"`
+		Digits  int    `json:"digits"`
+		Period  int    `json:"period"`
+		Counter int    `json:"counter"`
+		Pin     string `json:"pin"`
+	} `json:"info"`
+
+	// Name of the group, up to version 2 of the database
+	Group string `json:"group"`
+
+	// UUIDs of the groups, from version 3 of the database
+	Groups []string `json:"groups"`
+}
+
+// Hashing algorithms of Aegis
+var aegisAlgorithms = map[string]otp.Algorithm{
+	"SHA1":   otp.AlgorithmSHA1,
+	"SHA256": otp.AlgorithmSHA256,
+	"SHA512": otp.AlgorithmSHA512,
+	"MD5":    otp.AlgorithmMD5,
+}
+
+// Checks if the Aegis export needs a password
+func AegisEncrypted(data []byte) (bool, error) {
+	var file aegisFile
+
+	if err := json.Unmarshal(data, &file); err != nil || len(file.DB) == 0 {
+		return false, ERR_AEGIS_INVALID
+	}
+
+	return file.Header.Params != nil, nil
+}
+
+// Reads the tokens from an Aegis export, the password is only needed for encrypted ones
+// Each group becomes a folder, entries without a group go in the `Aegis` folder
+func ParseAegis(data []byte, password string) (Import, error) {
+	var file aegisFile
+
+	if err := json.Unmarshal(data, &file); err != nil || len(file.DB) == 0 {
+		return Import{}, ERR_AEGIS_INVALID
+	}
+
+	// Decrypt the database
+	rawDB := []byte(file.DB)
+
+	if file.Header.Params != nil {
+		var encoded string
+		var err error
+
+		if err = json.Unmarshal(file.DB, &encoded); err != nil {
+			return Import{}, ERR_AEGIS_INVALID
+		}
+
+		if rawDB, err = decryptAegisDB(file, encoded, password); err != nil {
+			return Import{}, err
+		}
+	}
+
+	// Parse the database
+	var db aegisDB
+
+	if err := json.Unmarshal(rawDB, &db); err != nil {
+		return Import{}, ERR_AEGIS_INVALID
+	}
+
+	// Group names by their UUID
+	groups := make(map[string]string)
+
+	for _, group := range db.Groups {
+		groups[group.UUID] = strings.TrimSpace(group.Name)
+	}
+
+	// Map entries
+	var imported Import
+
+	for _, entry := range db.Entries {
+		// Only the first group is kept, tokens live in a single folder
+		folder := strings.TrimSpace(entry.Group)
+
+		if len(entry.Groups) != 0 {
+			folder = groups[entry.Groups[0]]
+		}
+
+		if folder == "" {
+			folder = AEGIS_DEFAULT_FOLDER
+		}
+
+		// Token
+		token := Token{
+			Issuer:  entry.Issuer,
+			Account: entry.Name,
+			Secret:  entry.Info.Secret,
+			Period:  entry.Info.Period,
+			Digits:  entry.Info.Digits,
+		}
+
+		switch entry.Type {
+		case "totp":
+			token.Type = TokenTypeTOTP
+		case "hotp":
+			token.Type = TokenTypeHOTP
+			token.InitialCounter = entry.Info.Counter
+		case "steam":
+			token.Type = TokenTypeSteam
+		case "yandex":
+			token.Type = TokenTypeYandex
+			token.PIN = entry.Info.Pin
+		case "motp":
+			token.Type = TokenTypeMOTP
+			token.Secret = motpSecretFromAegis(entry.Info.Secret)
+			token.PIN = entry.Info.Pin
+		default:
+			imported.skip(entry.Issuer, entry.Name, fmt.Sprintf("unsupported token type %s", entry.Type))
+			continue
+		}
+
+		algorithm, ok := aegisAlgorithms[strings.ToUpper(entry.Info.Algo)]
+		if !ok {
+			imported.skip(entry.Issuer, entry.Name, fmt.Sprintf("unsupported algorithm %s", entry.Info.Algo))
+			continue
+		}
+
+		token.HashingAlgorithm = algorithm
+
+		token.Normalize()
+
+		imported.add(folder, token)
+	}
+
+	return imported, nil
+}
+
+// Converts the base32 secret of an Aegis mOTP entry to the hex that tlock keeps
+// An empty secret is returned if it is not valid base32, so that the entry is skipped
+func motpSecretFromAegis(secret string) string {
+	secret = strings.TrimRight(strings.ToUpper(strings.TrimSpace(secret)), "=")
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(key)
+}
+
+// Converts the hex secret of a mOTP token to the base32 that Aegis keeps
+func motpSecretToAegis(secret string) string {
+	key, err := hex.DecodeString(secret)
+	if err != nil {
+		return secret
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key)
+}
+
+// Decrypts the database of an encrypted Aegis export
+func decryptAegisDB(file aegisFile, encoded, password string) ([]byte, error) {
+	if password == "" {
+		return nil, ERR_AEGIS_PASSWORD_REQUIRED
+	}
+
+	// Unlock the master key with the first password slot that accepts the password
+	var masterKey []byte
+
+	for _, slot := range file.Header.Slots {
+		if slot.Type != aegisPasswordSlot {
+			continue
+		}
+
+		if key, err := slot.unlock(password); err == nil {
+			masterKey = key
+			break
+		}
+	}
+
+	if masterKey == nil {
+		return nil, ERR_AEGIS_PASSWORD_INVALID
+	}
+
+	// Decrypt the database with the master key
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ERR_AEGIS_INVALID
+	}
+
+	db, err := aegisOpen(masterKey, ciphertext, *file.Header.Params)
+	if err != nil {
+		return nil, ERR_AEGIS_INVALID
+	}
+
+	return db, nil
+}
+
+// Decrypts the master key in the slot with the password
+func (slot aegisSlot) unlock(password string) ([]byte, error) {
+	if slot.N <= 1 || slot.N > aegisMaxScryptN || slot.R <= 0 || slot.R > aegisMaxScryptR || slot.P <= 0 || slot.P > aegisMaxScryptP {
+		return nil, ERR_AEGIS_INVALID
+	}
+
+	salt, err := hex.DecodeString(slot.Salt)
+	if err != nil {
+		return nil, ERR_AEGIS_INVALID
+	}
+
+	encryptedKey, err := hex.DecodeString(slot.Key)
+	if err != nil {
+		return nil, ERR_AEGIS_INVALID
+	}
+
+	// Derive the key of the slot
+	key, err := scrypt.Key([]byte(password), salt, slot.N, slot.R, slot.P, KEY_SIZE)
+	if err != nil {
+		return nil, err
+	}
+
+	return aegisOpen(key, encryptedKey, slot.KeyParams)
+}
+
+// Decrypts AES-GCM ciphertext, whose nonce and tag are stored separately by Aegis
+func aegisOpen(key, ciphertext []byte, params aegisKeyParams) ([]byte, error) {
+	nonce, err := hex.DecodeString(params.Nonce)
+	if err != nil {
+		return nil, ERR_AEGIS_INVALID
+	}
+
+	tag, err := hex.DecodeString(params.Tag)
+	if err != nil {
+		return nil, ERR_AEGIS_INVALID
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+		return nil, ERR_AEGIS_INVALID
+	}
+
+	// Go expects the tag at the end of the ciphertext
+	sealed := append(append([]byte{}, ciphertext...), tag...)
+
+	return gcm.Open(nil, nonce, sealed, nil)
+}
@@ -0,0 +1,156 @@
+package tlockvault
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/pquerna/otp"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Database of a plain Aegis export, in the layout of version 3 of the database
+const aegisFixtureDB = `{
+	"version": 3,
+	"entries": [
+		{"type": "totp", "uuid": "1", "name": "me@example.com", "issuer": "GitHub", "groups": ["g-work"], "info": {"secret": "JBSWY3DPEHPK3PXP", "algo": "SHA1", "digits": 6, "period": 30}},
+		{"type": "hotp", "uuid": "2", "name": "alice", "issuer": "Bank", "groups": [], "info": {"secret": "GEZDGNBVGY3TQOJQ", "algo": "SHA256", "digits": 8, "counter": 7}},
+		{"type": "motp", "uuid": "3", "name": "vpn", "issuer": "Corp", "groups": ["g-work", "g-other"], "info": {"secret": "AERUKZ4JVPG66", "algo": "MD5", "digits": 6, "period": 10, "pin": "1234"}},
+		{"type": "steam", "uuid": "4", "name": "gamer", "issuer": "Steam", "groups": ["g-other"], "info": {"secret": "KRSXG5CTMVRXEZLU", "algo": "SHA1", "digits": 5, "period": 30}},
+		{"type": "unknown", "uuid": "5", "name": "x", "issuer": "Weird", "info": {"secret": "JBSWY3DPEHPK3PXP", "algo": "SHA1"}},
+		{"type": "totp", "uuid": "6", "name": "y", "issuer": "Odd", "info": {"secret": "JBSWY3DPEHPK3PXP", "algo": "SHA3"}},
+		{"type": "totp", "uuid": "7", "name": "z", "issuer": "Broken", "info": {"secret": "not base32!", "algo": "SHA1"}}
+	],
+	"groups": [
+		{"uuid": "g-work", "name": "Work"},
+		{"uuid": "g-other", "name": "Games"}
+	]
+}`
+
+// Plain Aegis export
+const aegisFixture = `{"version": 1, "header": {"slots": null, "params": null}, "db": ` + aegisFixtureDB + `}`
+
+// What the fixture imports as
+var aegisFixtureImport = Import{
+	Entries: []ImportEntry{
+		{Folder: "Work", Token: Token{Type: TokenTypeTOTP, Issuer: "GitHub", Account: "me@example.com", Secret: "JBSWY3DPEHPK3PXP", Period: 30, Digits: 6, HashingAlgorithm: otp.AlgorithmSHA1}},
+		{Folder: AEGIS_DEFAULT_FOLDER, Token: Token{Type: TokenTypeHOTP, Issuer: "Bank", Account: "alice", Secret: "GEZDGNBVGY3TQOJQ", Period: 30, Digits: 8, InitialCounter: 7, HashingAlgorithm: otp.AlgorithmSHA256}},
+		{Folder: "Work", Token: Token{Type: TokenTypeMOTP, Issuer: "Corp", Account: "vpn", Secret: "0123456789abcdef", PIN: "1234", Period: 10, Digits: 6, HashingAlgorithm: otp.AlgorithmMD5}},
+		{Folder: "Games", Token: Token{Type: TokenTypeSteam, Issuer: "Steam", Account: "gamer", Secret: "KRSXG5CTMVRXEZLU", Period: 30, Digits: 5, HashingAlgorithm: otp.AlgorithmSHA1}},
+	},
+	Skipped: []SkippedEntry{
+		{Name: "Weird:x", Reason: "unsupported token type unknown"},
+		{Name: "Odd:y", Reason: "unsupported algorithm SHA3"},
+		{Name: "Broken:z", Reason: "invalid secret"},
+	},
+}
+
+// Encrypts the database the way Aegis does, with a single password slot
+// The scrypt cost is low to keep the test fast
+func encryptAegis(t *testing.T, db, password string) []byte {
+	random := func(size int) []byte {
+		value := make([]byte, size)
+		rand.Read(value)
+
+		return value
+	}
+
+	// Seals the value, keeping the nonce and tag apart like Aegis does
+	aegisSeal := func(key, value []byte) ([]byte, aegisKeyParams) {
+		gcm, err := newGCM(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nonce := random(gcm.NonceSize())
+		sealed := gcm.Seal(nil, nonce, value, nil)
+		ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+		return ciphertext, aegisKeyParams{Nonce: hex.EncodeToString(nonce), Tag: hex.EncodeToString(tag)}
+	}
+
+	masterKey, salt := random(KEY_SIZE), random(32)
+
+	slotKey, err := scrypt.Key([]byte(password), salt, 1024, 8, 1, KEY_SIZE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encryptedKey, keyParams := aegisSeal(slotKey, masterKey)
+	encryptedDB, dbParams := aegisSeal(masterKey, []byte(db))
+
+	// Biometric slots are skipped
+	file := map[string]any{
+		"version": 1,
+		"header": map[string]any{
+			"slots": []aegisSlot{
+				{Type: 2, Key: hex.EncodeToString(random(32))},
+				{Type: aegisPasswordSlot, Key: hex.EncodeToString(encryptedKey), KeyParams: keyParams, N: 1024, R: 8, P: 1, Salt: hex.EncodeToString(salt)},
+			},
+			"params": dbParams,
+		},
+
+		// The database is base64 in encrypted exports
+		"db": base64.StdEncoding.EncodeToString(encryptedDB),
+	}
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestParseAegisPlain(t *testing.T) {
+	if encrypted, err := AegisEncrypted([]byte(aegisFixture)); err != nil || encrypted {
+		t.Fatalf("AegisEncrypted() = %v, %v, want false", encrypted, err)
+	}
+
+	imported, err := ParseAegis([]byte(aegisFixture), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(imported, aegisFixtureImport) {
+		t.Errorf("\n got %+v\nwant %+v", imported, aegisFixtureImport)
+	}
+}
+
+func TestParseAegisEncrypted(t *testing.T) {
+	data := encryptAegis(t, aegisFixtureDB, "hunter2")
+
+	if encrypted, err := AegisEncrypted(data); err != nil || !encrypted {
+		t.Fatalf("AegisEncrypted() = %v, %v, want true", encrypted, err)
+	}
+
+	imported, err := ParseAegis(data, "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(imported, aegisFixtureImport) {
+		t.Errorf("\n got %+v\nwant %+v", imported, aegisFixtureImport)
+	}
+
+	// Wrong or missing password
+	if _, err := ParseAegis(data, "wrong"); !errors.Is(err, ERR_AEGIS_PASSWORD_INVALID) {
+		t.Errorf("wrong password: got %v, want %v", err, ERR_AEGIS_PASSWORD_INVALID)
+	}
+
+	if _, err := ParseAegis(data, ""); !errors.Is(err, ERR_AEGIS_PASSWORD_REQUIRED) {
+		t.Errorf("no password: got %v, want %v", err, ERR_AEGIS_PASSWORD_REQUIRED)
+	}
+}
+
+func TestParseAegisInvalid(t *testing.T) {
+	for _, data := range []string{"", "{}", "[]", `{"db": "not an object"}`, "not json"} {
+		if _, err := ParseAegis([]byte(data), ""); !errors.Is(err, ERR_AEGIS_INVALID) {
+			t.Errorf("%q: got %v, want %v", data, err, ERR_AEGIS_INVALID)
+		}
+	}
+}
@@ -11,10 +11,13 @@ import (
 // Size of the salt
 var SALT_SIZE = 32
 
-// Derives a new key from the password to use it for cryptographic purposes using argon2id
+// Size of the derived key
+const KEY_SIZE = 32
+
+// Derives a new key from the password to use it for cryptographic purposes using the given KDF params
 // You can pass salt which will be used, or let the function generate one for you
 // It returns (key, salt, error)
-func GenerateKey(password string, salt []byte) ([]byte, []byte, error) {
+func GenerateKey(password string, salt []byte, params KDFParams) ([]byte, []byte, error) {
 	if salt == nil {
 		salt = make([]byte, SALT_SIZE)
 
@@ -23,83 +26,149 @@ func GenerateKey(password string, salt []byte) ([]byte, []byte, error) {
 		}
 	}
 
-	return argon2.Key([]byte(password), salt, 3, 32*1024, 4, 32), salt, nil
+	// Derive
+	switch params.KDF {
+	case KDFArgon2i:
+		return argon2.Key([]byte(password), salt, params.Time, params.Memory, params.Threads, KEY_SIZE), salt, nil
+	case KDFArgon2id:
+		return argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, KEY_SIZE), salt, nil
+	}
+
+	return nil, nil, ERR_VAULT_UNSUPPORTED
 }
 
-// Encrypts the given piece of byte array
-func Encrypt(password string, data []byte) ([]byte, error) {
-	// Ciphers and blocks
-	var blockCipher cipher.Block
-	var gcm cipher.AEAD
-	var err error
+// Initializes AES-GCM with the given key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	// Initialize AES
+	blockCipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
 
-	// Key
-	var key []byte
-	var salt []byte
+	// Initialize GCM
+	return cipher.NewGCM(blockCipher)
+}
 
-	// Generate key
-	if key, salt, err = GenerateKey(password, nil); err != nil {
+// Encrypts the data with the key, prepending a random nonce
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
 		return nil, err
 	}
 
-	// Initialize AES
-	if blockCipher, err = aes.NewCipher(key); err != nil {
+	// Random nonce
+	nonce := make([]byte, gcm.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
 		return nil, err
 	}
 
-	// Initialize GCM
-	if gcm, err = cipher.NewGCM(blockCipher); err != nil {
+	// Encrypt
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Decrypts the data sealed by `seal`
+func open(key, data, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
 		return nil, err
 	}
 
-	// Encrypt
-	nonce := make([]byte, gcm.NonceSize())
+	// Too short to even hold the nonce and the tag, it has been truncated
+	if len(data) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, ERR_VAULT_CORRUPT
+	}
 
-	// Encrypt
-	encryptedText := gcm.Seal(nonce, nonce, data, nil)
-	encryptedText = append(encryptedText, salt...)
+	// Get nonce
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	// Decrypt
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// Encrypts the given piece of byte array with the password
+// The output is a versioned vault container, with the header bound as the additional data
+func Encrypt(password string, data []byte) ([]byte, error) {
+	// Generate a new key
+	key, err := newSessionKey(password)
+	if err != nil {
+		return nil, err
+	}
 
-	// Return
-	return encryptedText, nil
+	// Encrypt
+	return key.seal(data)
 }
 
 // Decrypts the given piece of encrypted byte array
+// Both the legacy layout and the versioned containers are supported
 // It returns an error if decryption fails, because of the invalid key
 func Decrypt(password string, data []byte) ([]byte, error) {
-	// Ciphers and blocks
-	var blockCipher cipher.Block
-	var gcm cipher.AEAD
-	var decryptedText []byte
-	var err error
+	_, decrypted, err := unlock(password, data)
 
-	// Key
-	var key []byte
+	return decrypted, err
+}
 
-	// Extract salt and data
-	salt, data := data[len(data)-SALT_SIZE:], data[:len(data)-SALT_SIZE]
+// Decrypts the given vault file with the password
+// It returns the session key of the vault, which is nil for the formats that did not have one (legacy and version 1)
+func unlock(password string, data []byte) (*sessionKey, []byte, error) {
+	// Legacy vaults
+	if isLegacyFormat(data) {
+		decrypted, err := decryptLegacy(password, data)
 
-	// Generate key
-	if key, _, err = GenerateKey(password, salt); err != nil {
-		return nil, err
+		return nil, decrypted, err
 	}
 
-	// Initialize AES
-	if blockCipher, err = aes.NewCipher(key); err != nil {
-		return nil, err
+	// Parse header
+	header, headerRaw, data, err := parseHeader(data)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Initialize GCM
-	if gcm, err = cipher.NewGCM(blockCipher); err != nil {
-		return nil, err
+	// Version 1 derived the payload key directly from the password
+	if header.Version == 1 {
+		key, _, err := GenerateKey(password, header.Salt, header.KDF)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		decrypted, err := open(key, data, headerRaw)
+
+		return nil, decrypted, err
 	}
 
-	// Get nonce
-	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	// Unwrap the data key
+	key, err := unwrapDataKey(password, header)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Decrypt
-	if decryptedText, err = gcm.Open(nil, nonce, ciphertext, nil); err != nil {
+	// The password was right as the data key unwrapped fine, so failing here means that the payload is damaged
+	decrypted, err := open(key.dataKey, data, headerRaw)
+	if err != nil {
+		return nil, nil, ERR_VAULT_CORRUPT
+	}
+
+	return key, decrypted, nil
+}
+
+// Decrypts the legacy `nonce|ciphertext|salt` layout
+func decryptLegacy(password string, data []byte) ([]byte, error) {
+	// Too short to hold the salt, let alone anything else
+	if len(data) < SALT_SIZE {
+		return nil, ERR_VAULT_CORRUPT
+	}
+
+	// Extract salt and data
+	salt, data := data[len(data)-SALT_SIZE:], data[:len(data)-SALT_SIZE]
+
+	// Generate key
+	// Legacy vaults were always written with argon2i and the same cost
+	key, _, err := GenerateKey(password, salt, legacyKDFParams())
+	if err != nil {
 		return nil, err
 	}
 
-	return decryptedText, nil
+	// Decrypt
+	return open(key, data, nil)
 }
@@ -0,0 +1,303 @@
+package tlockvault
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Error representing that the export format is unknown
+var ERR_EXPORT_FORMAT = errors.New("Unknown export format, use uris, aegis or archive")
+
+// Error representing that the archive has no password
+var ERR_EXPORT_PASSWORD_EMPTY = errors.New("Encrypted archives need a password")
+
+// Error representing that the file is not a tlock archive, or the password is wrong
+var ERR_ARCHIVE_INVALID = errors.New("Not a tlock archive, or the password is wrong")
+
+// Export formats
+const (
+	// List of otpauth:// URIs, with a `# Folder: <name>` line before the tokens of each folder
+	ExportFormatURIs ExportFormat = "uris"
+
+	// JSON file in the format of the Aegis exports, not encrypted
+	ExportFormatAegis ExportFormat = "aegis"
+
+	// Password encrypted archive, in the same format as the vault itself
+	ExportFormatArchive ExportFormat = "archive"
+)
+
+// Format of an export
+type ExportFormat string
+
+// All the export formats
+var ExportFormats = []ExportFormat{ExportFormatURIs, ExportFormatAegis, ExportFormatArchive}
+
+// Checks if anyone who can read the export can read the tokens
+func (format ExportFormat) Plaintext() bool {
+	return format != ExportFormatArchive
+}
+
+// Extension of the files in the format
+func (format ExportFormat) Extension() string {
+	switch format {
+	case ExportFormatAegis:
+		return ".json"
+	case ExportFormatArchive:
+		return ".tlock"
+	}
+
+	return ".txt"
+}
+
+// Prefix of the lines with the folder name in the URI lists
+const uriListFolderPrefix = "# Folder: "
+
+// Returns the folders with the given names, or all of them if no names are given
+func (vault *Vault) SelectFolders(names []string) ([]Folder, error) {
+	if len(names) == 0 {
+		return vault.Folders, nil
+	}
+
+	folders := make([]Folder, 0, len(names))
+
+	for _, folder := range vault.Folders {
+		if slices.Contains(names, folder.Name) {
+			folders = append(folders, folder)
+		}
+	}
+
+	// Some name did not match
+	if len(folders) != len(names) {
+		return nil, ERR_FOLDER_NOT_FOUND
+	}
+
+	return folders, nil
+}
+
+// Exports the folders with the given names, or all of them if no names are given
+// The password is only used by encrypted archives
+func (vault *Vault) Export(format ExportFormat, names []string, password string) ([]byte, error) {
+	folders, err := vault.SelectFolders(names)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case ExportFormatURIs:
+		return exportURIs(folders), nil
+	case ExportFormatAegis:
+		return exportAegis(folders)
+	case ExportFormatArchive:
+		return exportArchive(folders, password)
+	}
+
+	return nil, ERR_EXPORT_FORMAT
+}
+
+// Writes the tokens as a list of URIs
+func exportURIs(folders []Folder) []byte {
+	var buffer bytes.Buffer
+
+	for index, folder := range folders {
+		if index != 0 {
+			buffer.WriteString("\n")
+		}
+
+		buffer.WriteString(uriListFolderPrefix + folder.Name + "\n")
+
+		for _, token := range folder.Tokens {
+			buffer.WriteString(token.URI() + "\n")
+		}
+	}
+
+	return buffer.Bytes()
+}
+
+// Reads the tokens from a list of URIs, as written by the URI export
+// Tokens before any folder line go in the given folder
+func ParseURIList(data []byte, folder string) (Import, error) {
+	var imported Import
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Folder
+		if name, found := strings.CutPrefix(line, uriListFolderPrefix); found {
+			folder = strings.TrimSpace(name)
+			continue
+		}
+
+		// Blank lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Named by its line number, the line might carry a secret
+		token, err := TokenFromURI(line)
+		if err != nil {
+			imported.skip("", fmt.Sprintf("line %d", number), "invalid otpauth:// URI")
+			continue
+		}
+
+		imported.add(folder, token)
+	}
+
+	return imported, scanner.Err()
+}
+
+// Generates a random UUID, used by Aegis to identify the entries and groups
+func newUUID() string {
+	uuid := make([]byte, 16)
+
+	// Reading from crypto/rand does not fail on any supported platform
+	rand.Read(uuid)
+
+	// Version 4, variant 1
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
+
+// Writes the tokens as a plain Aegis export, each folder becomes a group
+func exportAegis(folders []Folder) ([]byte, error) {
+	type info struct {
+		Secret  string `json:"secret"`
+		Algo    string `json:"algo"`
+		Digits  int    `json:"digits"`
+		Period  int    `json:"period,omitempty"`
+		Counter *int   `json:"counter,omitempty"`
+		Pin     string `json:"pin,omitempty"`
+	}
+
+	type entry struct {
+		Type     string   `json:"type"`
+		UUID     string   `json:"uuid"`
+		Name     string   `json:"name"`
+		Issuer   string   `json:"issuer"`
+		Note     string   `json:"note"`
+		Favorite bool     `json:"favorite"`
+		Icon     *string  `json:"icon"`
+		Info     info     `json:"info"`
+		Groups   []string `json:"groups"`
+	}
+
+	type group struct {
+		UUID string `json:"uuid"`
+		Name string `json:"name"`
+	}
+
+	db := struct {
+		Version int     `json:"version"`
+		Entries []entry `json:"entries"`
+		Groups  []group `json:"groups"`
+	}{Version: 3, Entries: []entry{}, Groups: []group{}}
+
+	for _, folder := range folders {
+		groupUUID := newUUID()
+		db.Groups = append(db.Groups, group{UUID: groupUUID, Name: folder.Name})
+
+		for _, token := range folder.Tokens {
+			item := entry{
+				Type:   "totp",
+				UUID:   newUUID(),
+				Name:   token.Account,
+				Issuer: token.Issuer,
+				Info: info{
+					Secret: token.Secret,
+					Algo:   token.HashingAlgorithm.String(),
+					Digits: token.Digits,
+					Period: token.Period,
+				},
+				Groups: []string{groupUUID},
+			}
+
+			switch token.Type {
+			case TokenTypeSteam:
+				item.Type = "steam"
+			case TokenTypeYandex:
+				item.Type = "yandex"
+				item.Info.Pin = token.PIN
+			case TokenTypeMOTP:
+				item.Type = "motp"
+				item.Info.Secret = motpSecretToAegis(token.Secret)
+				item.Info.Pin = token.PIN
+			}
+
+			if token.Type == TokenTypeHOTP {
+				counter := token.Counter()
+
+				item.Type = "hotp"
+				item.Info.Period = 0
+				item.Info.Counter = &counter
+			}
+
+			db.Entries = append(db.Entries, item)
+		}
+	}
+
+	// Plain exports have no slots and no params
+	file := struct {
+		Version int `json:"version"`
+		Header  struct {
+			Slots  []any `json:"slots"`
+			Params any   `json:"params"`
+		} `json:"header"`
+		DB any `json:"db"`
+	}{Version: 1, DB: db}
+
+	data, err := json.MarshalIndent(file, "", "    ")
+
+	return append(data, '\n'), err
+}
+
+// Writes the folders as an archive encrypted with the password
+func exportArchive(folders []Folder, password string) ([]byte, error) {
+	if password == "" {
+		return nil, ERR_EXPORT_PASSWORD_EMPTY
+	}
+
+	// Archives are vault files of their own, with a key of their own
+	key, err := newSessionKey(password)
+	if err != nil {
+		return nil, err
+	}
+
+	serialized, err := encodePayload(folders)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.seal(serialized)
+}
+
+// Reads the tokens from an encrypted tlock archive, the folders are kept as they are
+func ParseArchive(data []byte, password string) (Import, error) {
+	_, decrypted, err := unlock(password, data)
+	if err != nil {
+		return Import{}, ERR_ARCHIVE_INVALID
+	}
+
+	folders, err := decodePayload(isLegacyFormat(data), decrypted)
+	if err != nil {
+		return Import{}, err
+	}
+
+	var imported Import
+
+	for _, folder := range folders {
+		for _, token := range folder.Tokens {
+			imported.add(folder.Name, token)
+		}
+	}
+
+	return imported, nil
+}
@@ -0,0 +1,169 @@
+package tlockvault
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pquerna/otp"
+)
+
+// Folders of the vault that is exported, with a token of every type
+var exportFixture = []Folder{
+	{Name: "Work", Tokens: []Token{
+		{Type: TokenTypeTOTP, Issuer: "GitHub", Account: "me@example.com", Secret: "JBSWY3DPEHPK3PXP", Period: 60, Digits: 8, HashingAlgorithm: otp.AlgorithmSHA512, Image: "https://example.com/github.png", Parameters: []URIParameter{{Key: "color", Value: "black"}}},
+		{Type: TokenTypeHOTP, Issuer: "Bank", Account: "alice", Secret: "GEZDGNBVGY3TQOJQ", Period: 30, Digits: 6, InitialCounter: 5, UsageCounter: 3, HashingAlgorithm: otp.AlgorithmSHA256},
+	}},
+	{Name: "Other", Tokens: []Token{
+		{Type: TokenTypeSteam, Issuer: "Steam", Account: "gamer", Secret: "KRSXG5CTMVRXEZLU"},
+		{Type: TokenTypeYandex, Issuer: "Yandex", Account: "ivan", Secret: "JBSWY3DPEHPK3PXPJBSWY3DPEE", PIN: "1234"},
+		{Type: TokenTypeMOTP, Issuer: "Corp", Account: "vpn", Secret: "0123456789abcdef", PIN: "5678"},
+	}},
+}
+
+// Creates a vault with the folders of the fixture
+func exportVault(t *testing.T) *Vault {
+	vault, err := Initialize(filepath.Join(t.TempDir(), "vault.dat"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { vault.Close() })
+
+	for _, folder := range exportFixture {
+		if err := vault.AddFolder(folder.Name); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, token := range folder.Tokens {
+			token.Normalize()
+
+			if err := vault.AddTokenFromToken(folder.Name, token); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	return vault
+}
+
+// Entries that importing the vault should give back, changed by the `lossy` function for what the format cannot keep
+func expectedImport(vault *Vault, lossy func(token *Token)) []ImportEntry {
+	entries := make([]ImportEntry, 0)
+
+	for _, folder := range vault.Folders {
+		for _, token := range folder.Tokens {
+			lossy(&token)
+
+			entries = append(entries, ImportEntry{Folder: folder.Name, Token: token})
+		}
+	}
+
+	return entries
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	vault := exportVault(t)
+
+	// Text exports have no IDs, and HOTP tokens start again from their current counter
+	textual := func(token *Token) {
+		token.ID = ""
+		token.InitialCounter, token.UsageCounter = token.Counter(), 0
+	}
+
+	tests := []struct {
+		format ExportFormat
+		parse  func(data []byte) (Import, error)
+		lossy  func(token *Token)
+	}{
+		{
+			ExportFormatURIs,
+			func(data []byte) (Import, error) { return ParseURIList(data, "Imported") },
+			textual,
+		},
+		{
+			ExportFormatAegis,
+			func(data []byte) (Import, error) { return ParseAegis(data, "") },
+			func(token *Token) {
+				textual(token)
+
+				// Aegis has no place for the image URL and the other URI parameters
+				token.Image, token.Parameters = "", nil
+			},
+		},
+		{
+			ExportFormatArchive,
+			func(data []byte) (Import, error) { return ParseArchive(data, "hunter2") },
+			func(token *Token) {},
+		},
+	}
+
+	for _, test := range tests {
+		data, err := vault.Export(test.format, nil, "hunter2")
+		if err != nil {
+			t.Errorf("%s: %v", test.format, err)
+			continue
+		}
+
+		imported, err := test.parse(data)
+		if err != nil {
+			t.Errorf("%s: %v", test.format, err)
+			continue
+		}
+
+		if want := expectedImport(vault, test.lossy); !reflect.DeepEqual(imported.Entries, want) || len(imported.Skipped) != 0 {
+			t.Errorf("%s:\n got %+v, skipped %+v\nwant %+v", test.format, imported.Entries, imported.Skipped, want)
+		}
+	}
+}
+
+func TestExportFolders(t *testing.T) {
+	vault := exportVault(t)
+
+	data, err := vault.Export(ExportFormatArchive, []string{"Other"}, "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := ParseArchive(data, "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range imported.Entries {
+		if entry.Folder != "Other" {
+			t.Errorf("exported token %+v of the folder %q", entry.Token, entry.Folder)
+		}
+	}
+
+	if len(imported.Entries) != len(exportFixture[1].Tokens) {
+		t.Errorf("got %d tokens, want %d", len(imported.Entries), len(exportFixture[1].Tokens))
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	vault := exportVault(t)
+
+	if _, err := vault.Export(ExportFormatURIs, []string{"Work", "Missing"}, ""); !errors.Is(err, ERR_FOLDER_NOT_FOUND) {
+		t.Errorf("unknown folder: got %v, want %v", err, ERR_FOLDER_NOT_FOUND)
+	}
+
+	if _, err := vault.Export("csv", nil, ""); !errors.Is(err, ERR_EXPORT_FORMAT) {
+		t.Errorf("unknown format: got %v, want %v", err, ERR_EXPORT_FORMAT)
+	}
+
+	if _, err := vault.Export(ExportFormatArchive, nil, ""); !errors.Is(err, ERR_EXPORT_PASSWORD_EMPTY) {
+		t.Errorf("archive without a password: got %v, want %v", err, ERR_EXPORT_PASSWORD_EMPTY)
+	}
+
+	// Archives only open with their own password
+	data, err := vault.Export(ExportFormatArchive, nil, "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParseArchive(data, "password"); !errors.Is(err, ERR_ARCHIVE_INVALID) {
+		t.Errorf("wrong password: got %v, want %v", err, ERR_ARCHIVE_INVALID)
+	}
+}
@@ -0,0 +1,172 @@
+package tlockvault
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"time"
+)
+
+// Fingerprint of the vault file as we last read or wrote it
+type fileFingerprint struct {
+	// Modification time
+	modTime time.Time
+
+	// Size
+	size int64
+
+	// Hash of the contents
+	hash [sha256.Size]byte
+}
+
+// Takes the fingerprint of the vault file with the given contents
+func fingerprintOf(path string, contents []byte) fileFingerprint {
+	fingerprint := fileFingerprint{hash: sha256.Sum256(contents)}
+
+	if stat, err := os.Stat(path); err == nil {
+		fingerprint.modTime = stat.ModTime()
+		fingerprint.size = stat.Size()
+	}
+
+	return fingerprint
+}
+
+// Remembers the contents of the vault file as known
+// The writer lock must be held
+func (vault *Vault) setKnownContents(contents []byte) {
+	vault.writer.disk = fingerprintOf(vault.path, contents)
+}
+
+// Checks if the vault file has been changed on the disk by someone else since we last read or wrote it
+func (vault *Vault) ChangedOnDisk() bool {
+	vault.writer.lock.Lock()
+	defer vault.writer.lock.Unlock()
+
+	stat, err := os.Stat(vault.path)
+
+	// If it is gone, there is nothing to reload
+	if err != nil {
+		return false
+	}
+
+	// Cheap check first
+	known := vault.writer.disk
+
+	if stat.ModTime().Equal(known.modTime) && stat.Size() == known.size {
+		return false
+	}
+
+	// Compare the contents, the file may just have been touched
+	contents, err := os.ReadFile(vault.path)
+	if err != nil {
+		return false
+	}
+
+	if hash := sha256.Sum256(contents); bytes.Equal(hash[:], known.hash[:]) {
+		// Same contents, remember the new stat
+		vault.setKnownContents(contents)
+
+		return false
+	}
+
+	return true
+}
+
+// Ignores the current changes on the disk, they will be overwritten by the next write
+func (vault *Vault) IgnoreDiskChanges() {
+	vault.writer.lock.Lock()
+	defer vault.writer.lock.Unlock()
+
+	vault.readKnownContents()
+}
+
+// Reads the folders from the vault file on the disk
+// The writer lock must be held
+func (vault *Vault) readFromDisk() ([]Folder, error) {
+	// Read
+	raw, err := os.ReadFile(vault.path)
+	if err != nil {
+		return nil, ERR_VAULT_DELETED
+	}
+
+	// Decrypt with our data key, we don't have the password
+	key, decrypted, err := vault.key.open(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode
+	folders, err := decodePayload(false, decrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	// Adopt the header, someone else might have changed the password
+	vault.key = key
+
+	// The contents are now known
+	vault.setKnownContents(raw)
+
+	return folders, nil
+}
+
+// Replaces the folders with the ones in the vault file on the disk, discarding the ones in memory
+func (vault *Vault) Reload() error {
+	vault.writer.lock.Lock()
+	defer vault.writer.lock.Unlock()
+
+	// We cannot decrypt it, ask the remote
+	if vault.remote != nil {
+		return vault.reloadRemote()
+	}
+
+	folders, err := vault.readFromDisk()
+
+	if err == nil {
+		vault.Folders = folders
+	}
+
+	return err
+}
+
+// Merges the folders in the vault file on the disk into the ones in memory
+// Folders and tokens that only exist on the disk are added, nothing is removed
+func (vault *Vault) MergeFromDisk() error {
+	if err := vault.writable(); err != nil {
+		return err
+	}
+
+	// Hold the writer lock while the folders change, the writer reads them
+	vault.writer.lock.Lock()
+
+	folders, err := vault.readFromDisk()
+
+	if err != nil {
+		vault.writer.lock.Unlock()
+
+		return err
+	}
+
+	for _, folder := range folders {
+		// Whole folder is new
+		index := vault.findFolder(folder.Name)
+
+		if index == -1 {
+			vault.Folders = append(vault.Folders, folder)
+
+			continue
+		}
+
+		// Add the tokens we don't know about
+		for _, token := range folder.Tokens {
+			if !vault.tokenExists(token.ID) {
+				vault.Folders[index].Tokens = append(vault.Folders[index].Tokens, token)
+			}
+		}
+	}
+
+	vault.writer.lock.Unlock()
+
+	// Write
+	return vault.write()
+}
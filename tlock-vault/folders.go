@@ -10,13 +10,17 @@ import (
 func (vault *Vault) AddFolder(name string) error {
 	var err error
 
+	if err = vault.writable(); err != nil {
+		return err
+	}
+
 	// Validate
 	if name, err = vault.validateFolderName(name); err == nil {
 		// Add folder
 		vault.Folders = append(vault.Folders, Folder{Name: name})
 
 		// Write
-		vault.write()
+		err = vault.write()
 	}
 
 	// Return
@@ -27,13 +31,17 @@ func (vault *Vault) AddFolder(name string) error {
 func (vault *Vault) RenameFolder(old, newName string) error {
 	var err error
 
+	if err = vault.writable(); err != nil {
+		return err
+	}
+
 	// Validate folder name
 	if newName, err = vault.validateFolderName(newName); err == nil {
 		// Update
 		vault.Folders[vault.findFolder(old)].Name = newName
 
 		// Write
-		vault.write()
+		err = vault.write()
 	}
 
 	// Return
@@ -42,52 +50,65 @@ func (vault *Vault) RenameFolder(old, newName string) error {
 
 // Returns all the tokens inside of a folder
 func (vault *Vault) GetTokens(folder string) []Token {
-	return vault.Folders[vault.findFolder(folder)].Tokens
+	// Folder may have vanished after a reload
+	if index := vault.findFolder(folder); index != -1 {
+		return vault.Folders[index].Tokens
+	}
+
+	return []Token{}
 }
 
 // Deletes a folder by its name
-func (vault *Vault) DeleteFolder(name string) {
+func (vault *Vault) DeleteFolder(name string) error {
+	if err := vault.writable(); err != nil {
+		return err
+	}
+
 	if index := vault.findFolder(name); index != -1 {
 		// Remove
 		vault.Folders = utils.Remove(vault.Folders, index)
 
 		// Write
-		vault.write()
+		return vault.write()
 	}
+
+	return nil
 }
 
 // Moves the folder up
-func (vault *Vault) MoveFolderUp(name string) bool {
+func (vault *Vault) MoveFolderUp(name string) (bool, error) {
+	if err := vault.writable(); err != nil {
+		return false, err
+	}
+
 	// We will skip if the folder is already at top
 	if index := vault.findFolder(name); index != 0 {
 		// Swap
 		vault.Folders = utils.Swap(vault.Folders, index, index-1)
 
 		// Wrap
-		vault.write()
-
-		// True
-		return true
+		return true, vault.write()
 	}
 
-	return false
+	return false, nil
 }
 
 // Moves the folder down
-func (vault *Vault) MoveFolderDown(name string) bool {
+func (vault *Vault) MoveFolderDown(name string) (bool, error) {
+	if err := vault.writable(); err != nil {
+		return false, err
+	}
+
 	// We will skip if the folder is already at bottom
 	if index := vault.findFolder(name); index != len(vault.Folders)-1 {
 		// Swap
 		vault.Folders = utils.Swap(vault.Folders, index, index+1)
 
 		// Wrap
-		vault.write()
-
-		// True
-		return true
+		return true, vault.write()
 	}
 
-	return false
+	return false, nil
 }
 
 // Checks if the folder with the name exists
@@ -0,0 +1,215 @@
+package tlockvault
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Magic string that every versioned vault file starts with
+var VAULT_MAGIC = []byte("TLOCKVLT")
+
+// Current version of the vault file format
+//
+// 1: payload encrypted with a key derived from the password
+// 2: payload encrypted with a random data key, which is wrapped by a key derived from the password
+const VAULT_FORMAT_VERSION = 2
+
+// Key derivation functions
+const (
+	KDFArgon2i = iota + 1
+	KDFArgon2id
+)
+
+// Upper bounds for the KDF cost that we are willing to run
+// Prevents a malicious header from making us allocate absurd amounts of memory or spin forever
+const (
+	KDF_MAX_TIME   = 64
+	KDF_MAX_MEMORY = 4 * 1024 * 1024 // KiB
+)
+
+// Error representing that the vault was written by a newer version of tlock
+var ERR_VAULT_UNSUPPORTED = errors.New("The vault was created by a newer version of tlock, please update")
+
+// Parameters for the key derivation function
+type KDFParams struct {
+	// KDF to use
+	KDF uint8
+
+	// Number of iterations
+	Time uint32
+
+	// Memory to use (in KiB)
+	Memory uint32
+
+	// Number of threads
+	Threads uint8
+}
+
+// Returns the KDF params used for writing new vaults
+func DefaultKDFParams() KDFParams {
+	return KDFParams{
+		KDF:     KDFArgon2id,
+		Time:    3,
+		Memory:  32 * 1024,
+		Threads: 4,
+	}
+}
+
+// Returns the KDF params that legacy vaults were written with
+func legacyKDFParams() KDFParams {
+	return KDFParams{
+		KDF:     KDFArgon2i,
+		Time:    3,
+		Memory:  32 * 1024,
+		Threads: 4,
+	}
+}
+
+// Validates if the KDF params are sane to be used
+func (params KDFParams) validate() error {
+	if params.KDF != KDFArgon2i && params.KDF != KDFArgon2id {
+		return ERR_VAULT_UNSUPPORTED
+	}
+
+	if params.Time == 0 || params.Time > KDF_MAX_TIME || params.Threads == 0 || params.Memory == 0 || params.Memory > KDF_MAX_MEMORY {
+		return ERR_VAULT_CORRUPT
+	}
+
+	return nil
+}
+
+// Header of a versioned vault file
+// The serialized header is authenticated as the additional data of GCM
+//
+// Layout: magic | version (u8) | kdf (u8) | time (u32) | memory (u32) | threads (u8) | salt length (u8) | salt
+// Since version 2, it is followed by: wrapped key length (u8) | wrapped key
+type Header struct {
+	// Version of the format
+	Version uint8
+
+	// KDF parameters used to derive the key
+	KDF KDFParams
+
+	// Salt for the KDF
+	Salt []byte
+
+	// Data key wrapped with the key derived from the password [only since version 2]
+	WrappedKey []byte
+}
+
+// Serializes the header
+func (header Header) Marshal() []byte {
+	raw := header.marshalKDF()
+
+	// Wrapped data key
+	if header.Version >= 2 {
+		raw = append(raw, uint8(len(header.WrappedKey)))
+		raw = append(raw, header.WrappedKey...)
+	}
+
+	return raw
+}
+
+// Serializes the part of the header that describes how to derive the key from the password
+func (header Header) marshalKDF() []byte {
+	var buffer bytes.Buffer
+
+	// Magic and version
+	buffer.Write(VAULT_MAGIC)
+	buffer.WriteByte(header.Version)
+
+	// KDF parameters
+	buffer.WriteByte(header.KDF.KDF)
+	binary.Write(&buffer, binary.BigEndian, header.KDF.Time)
+	binary.Write(&buffer, binary.BigEndian, header.KDF.Memory)
+	buffer.WriteByte(header.KDF.Threads)
+
+	// Salt
+	buffer.WriteByte(uint8(len(header.Salt)))
+	buffer.Write(header.Salt)
+
+	// Return
+	return buffer.Bytes()
+}
+
+// Parses the header from the start of the data
+// It returns (header, header bytes, rest of the data, error)
+func parseHeader(data []byte) (Header, []byte, []byte, error) {
+	var header Header
+
+	reader := bytes.NewReader(data)
+
+	// Skip magic
+	reader.Seek(int64(len(VAULT_MAGIC)), io.SeekStart)
+
+	// Fixed size part of the header
+	var fixed struct {
+		Version uint8
+		KDF     uint8
+		Time    uint32
+		Memory  uint32
+		Threads uint8
+		SaltLen uint8
+	}
+
+	if err := binary.Read(reader, binary.BigEndian, &fixed); err != nil {
+		return header, nil, nil, ERR_VAULT_CORRUPT
+	}
+
+	// Refuse versions we don't know about
+	if fixed.Version == 0 || fixed.Version > VAULT_FORMAT_VERSION {
+		return header, nil, nil, ERR_VAULT_UNSUPPORTED
+	}
+
+	// Read salt
+	salt := make([]byte, fixed.SaltLen)
+
+	if _, err := io.ReadFull(reader, salt); err != nil {
+		return header, nil, nil, ERR_VAULT_CORRUPT
+	}
+
+	// Read wrapped data key
+	var wrappedKey []byte
+
+	if fixed.Version >= 2 {
+		length, err := reader.ReadByte()
+		if err != nil {
+			return header, nil, nil, ERR_VAULT_CORRUPT
+		}
+
+		wrappedKey = make([]byte, length)
+
+		if _, err := io.ReadFull(reader, wrappedKey); err != nil {
+			return header, nil, nil, ERR_VAULT_CORRUPT
+		}
+	}
+
+	header = Header{
+		Version: fixed.Version,
+		KDF: KDFParams{
+			KDF:     fixed.KDF,
+			Time:    fixed.Time,
+			Memory:  fixed.Memory,
+			Threads: fixed.Threads,
+		},
+		Salt:       salt,
+		WrappedKey: wrappedKey,
+	}
+
+	// Validate KDF
+	if err := header.KDF.validate(); err != nil {
+		return header, nil, nil, err
+	}
+
+	// Split
+	headerLen := len(data) - reader.Len()
+
+	return header, data[:headerLen], data[headerLen:], nil
+}
+
+// Checks if the data is in the legacy `nonce|ciphertext|salt` layout
+func isLegacyFormat(data []byte) bool {
+	return !bytes.HasPrefix(data, VAULT_MAGIC)
+}
@@ -0,0 +1,88 @@
+package tlockvault
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Header of a version 2 vault, with a made up wrapped key
+func testHeader() Header {
+	return Header{
+		Version:    VAULT_FORMAT_VERSION,
+		KDF:        DefaultKDFParams(),
+		Salt:       bytes.Repeat([]byte{1}, SALT_SIZE),
+		WrappedKey: bytes.Repeat([]byte{2}, 60),
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	v1 := testHeader()
+	v1.Version, v1.WrappedKey = 1, nil
+
+	for _, header := range []Header{testHeader(), v1} {
+		raw := header.Marshal()
+		payload := []byte("payload")
+
+		parsed, parsedRaw, rest, err := parseHeader(append(raw, payload...))
+		if err != nil {
+			t.Errorf("version %d: %v", header.Version, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(parsed, header) || !bytes.Equal(parsedRaw, raw) || !bytes.Equal(rest, payload) {
+			t.Errorf("version %d: got %+v, %x, %q", header.Version, parsed, parsedRaw, rest)
+		}
+	}
+}
+
+func TestParseHeaderRejects(t *testing.T) {
+	raw := testHeader().Marshal()
+
+	// Changes a single byte of the header
+	with := func(index int, value byte) []byte {
+		changed := bytes.Clone(raw)
+		changed[index] = value
+
+		return changed
+	}
+
+	// Offsets in the header
+	version := len(VAULT_MAGIC)
+	kdf := version + 1
+	memory := kdf + 1 + 4
+	saltLength := memory + 4 + 1
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"only the magic", VAULT_MAGIC, ERR_VAULT_CORRUPT},
+		{"truncated in the KDF params", raw[:memory], ERR_VAULT_CORRUPT},
+		{"truncated in the salt", raw[:saltLength+10], ERR_VAULT_CORRUPT},
+		{"truncated before the wrapped key", raw[:saltLength+1+SALT_SIZE], ERR_VAULT_CORRUPT},
+		{"truncated in the wrapped key", raw[:len(raw)-1], ERR_VAULT_CORRUPT},
+		{"version 0", with(version, 0), ERR_VAULT_UNSUPPORTED},
+		{"newer version", with(version, VAULT_FORMAT_VERSION+1), ERR_VAULT_UNSUPPORTED},
+		{"unknown KDF", with(kdf, 9), ERR_VAULT_UNSUPPORTED},
+		{"absurd memory cost", with(memory, 0xff), ERR_VAULT_CORRUPT},
+	}
+
+	for _, test := range tests {
+		if _, _, _, err := parseHeader(test.data); !errors.Is(err, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestIsLegacyFormat(t *testing.T) {
+	if isLegacyFormat(testHeader().Marshal()) {
+		t.Error("versioned vault detected as legacy")
+	}
+
+	if !isLegacyFormat(bytes.Repeat([]byte{7}, 64)) {
+		t.Error("legacy vault detected as versioned")
+	}
+}
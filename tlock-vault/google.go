@@ -0,0 +1,265 @@
+package tlockvault
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pquerna/otp"
+)
+
+// Error representing that the URI is not a valid Google Authenticator export
+var ERR_MIGRATION_INVALID = errors.New("Not a valid Google Authenticator export, try scanning it again")
+
+// Scheme of the Google Authenticator export URIs
+const migrationScheme = "otpauth-migration"
+
+// Accounts in one QR code of a Google Authenticator export
+// Large exports are split into a batch of several QR codes
+type MigrationPart struct {
+	Import
+
+	// Identifies the batch, same for all of its QR codes
+	BatchID int
+
+	// Index of this QR code in the batch, starting from 0
+	BatchIndex int
+
+	// Number of QR codes in the batch
+	BatchSize int
+}
+
+// Checks if the URI is a Google Authenticator export, rather than a single token
+func IsMigrationURI(uri string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(uri)), migrationScheme+"://")
+}
+
+// Reads the accounts from a Google Authenticator `otpauth-migration://offline?data=` URI into the given folder
+func ParseGoogleMigration(uri, folder string) (MigrationPart, error) {
+	parsed, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || !strings.EqualFold(parsed.Scheme, migrationScheme) {
+		return MigrationPart{}, ERR_MIGRATION_INVALID
+	}
+
+	// Query decoding turns unescaped `+` of the base64 data into spaces
+	data := strings.ReplaceAll(parsed.Query().Get("data"), " ", "+")
+
+	if data == "" {
+		return MigrationPart{}, ERR_MIGRATION_INVALID
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		if payload, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "=")); err != nil {
+			return MigrationPart{}, ERR_MIGRATION_INVALID
+		}
+	}
+
+	return decodeMigrationPayload(payload, folder)
+}
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// Field of a protobuf message
+type protoField struct {
+	number int
+
+	// Value of varint fields
+	varint uint64
+
+	// Value of length delimited fields
+	bytes []byte
+}
+
+// Decodes the fields of a protobuf message, without knowing its schema
+func decodeProtoFields(data []byte) ([]protoField, error) {
+	fields := make([]protoField, 0)
+
+	for len(data) != 0 {
+		// Key
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, ERR_MIGRATION_INVALID
+		}
+
+		data = data[n:]
+		field := protoField{number: int(key >> 3)}
+
+		// Value
+		switch key & 7 {
+		case wireVarint:
+			if field.varint, n = binary.Uvarint(data); n <= 0 {
+				return nil, ERR_MIGRATION_INVALID
+			}
+
+			data = data[n:]
+
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return nil, ERR_MIGRATION_INVALID
+			}
+
+			field.bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+
+		case wireFixed64, wireFixed32:
+			size := 8
+
+			if key&7 == wireFixed32 {
+				size = 4
+			}
+
+			if len(data) < size {
+				return nil, ERR_MIGRATION_INVALID
+			}
+
+			data = data[size:]
+
+		default:
+			return nil, ERR_MIGRATION_INVALID
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// Hashing algorithms of the export
+var migrationAlgorithms = map[uint64]otp.Algorithm{
+	0: otp.AlgorithmSHA1,
+	1: otp.AlgorithmSHA1,
+	2: otp.AlgorithmSHA256,
+	3: otp.AlgorithmSHA512,
+	4: otp.AlgorithmMD5,
+}
+
+// Decodes the `MigrationPayload` message
+//
+//	message MigrationPayload {
+//	  repeated OtpParameters otp_parameters = 1;
+//	  int32 version = 2;
+//	  int32 batch_size = 3;
+//	  int32 batch_index = 4;
+//	  int32 batch_id = 5;
+//	}
+func decodeMigrationPayload(payload []byte, folder string) (MigrationPart, error) {
+	fields, err := decodeProtoFields(payload)
+	if err != nil {
+		return MigrationPart{}, err
+	}
+
+	part := MigrationPart{BatchSize: 1}
+
+	for _, field := range fields {
+		switch field.number {
+		case 1:
+			if err := part.addAccount(field.bytes, folder); err != nil {
+				return MigrationPart{}, err
+			}
+		case 3:
+			part.BatchSize = int(field.varint)
+		case 4:
+			part.BatchIndex = int(field.varint)
+		case 5:
+			part.BatchID = int(field.varint)
+		}
+	}
+
+	if part.BatchSize <= 0 || part.BatchIndex < 0 || part.BatchIndex >= part.BatchSize {
+		return MigrationPart{}, ERR_MIGRATION_INVALID
+	}
+
+	return part, nil
+}
+
+// Decodes the `OtpParameters` message and adds the account
+//
+//	message OtpParameters {
+//	  bytes secret = 1;
+//	  string name = 2;
+//	  string issuer = 3;
+//	  Algorithm algorithm = 4;   // SHA1 = 1, SHA256 = 2, SHA512 = 3, MD5 = 4
+//	  DigitCount digits = 5;     // SIX = 1, EIGHT = 2
+//	  OtpType type = 6;          // HOTP = 1, TOTP = 2
+//	  int64 counter = 7;
+//	}
+func (part *MigrationPart) addAccount(message []byte, folder string) error {
+	fields, err := decodeProtoFields(message)
+	if err != nil {
+		return err
+	}
+
+	var secret []byte
+	var algorithm, digits, otpType uint64
+
+	token := Token{Type: TokenTypeTOTP, Period: 30, Digits: 6}
+
+	for _, field := range fields {
+		switch field.number {
+		case 1:
+			secret = field.bytes
+		case 2:
+			token.Account = string(field.bytes)
+		case 3:
+			token.Issuer = string(field.bytes)
+		case 4:
+			algorithm = field.varint
+		case 5:
+			digits = field.varint
+		case 6:
+			otpType = field.varint
+		case 7:
+			token.InitialCounter = int(field.varint)
+		}
+	}
+
+	// The name is usually `issuer:account`
+	if issuer, account, found := strings.Cut(token.Account, ":"); found && (token.Issuer == "" || issuer == token.Issuer) {
+		token.Issuer = issuer
+		token.Account = strings.TrimSpace(account)
+	}
+
+	// Secrets are raw bytes in the export
+	token.Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+
+	// Type
+	switch otpType {
+	case 0, 2:
+		token.Type = TokenTypeTOTP
+	case 1:
+		token.Type = TokenTypeHOTP
+	default:
+		part.skip(token.Issuer, token.Account, fmt.Sprintf("unsupported token type %d", otpType))
+		return nil
+	}
+
+	// Digits
+	if digits == 2 {
+		token.Digits = 8
+	}
+
+	// Algorithm
+	hash, ok := migrationAlgorithms[algorithm]
+	if !ok {
+		part.skip(token.Issuer, token.Account, fmt.Sprintf("unsupported algorithm %d", algorithm))
+		return nil
+	}
+
+	token.HashingAlgorithm = hash
+
+	part.add(folder, token)
+
+	return nil
+}
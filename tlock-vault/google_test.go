@@ -0,0 +1,151 @@
+package tlockvault
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/pquerna/otp"
+)
+
+// Encodes a varint field of a protobuf message
+func protoVarint(number int, value uint64) []byte {
+	data := appendVarint(nil, uint64(number)<<3|wireVarint)
+
+	return appendVarint(data, value)
+}
+
+// Encodes a length delimited field of a protobuf message
+func protoBytes(number int, value []byte) []byte {
+	data := appendVarint(nil, uint64(number)<<3|wireBytes)
+	data = appendVarint(data, uint64(len(value)))
+
+	return append(data, value...)
+}
+
+// Appends the value as a protobuf varint
+func appendVarint(data []byte, value uint64) []byte {
+	for value >= 0x80 {
+		data = append(data, byte(value)|0x80)
+		value >>= 7
+	}
+
+	return append(data, byte(value))
+}
+
+// Encodes an `OtpParameters` message
+func otpParameters(secret []byte, name, issuer string, algorithm, digits, otpType, counter uint64) []byte {
+	var message []byte
+
+	message = append(message, protoBytes(1, secret)...)
+	message = append(message, protoBytes(2, []byte(name))...)
+	message = append(message, protoBytes(3, []byte(issuer))...)
+	message = append(message, protoVarint(4, algorithm)...)
+	message = append(message, protoVarint(5, digits)...)
+	message = append(message, protoVarint(6, otpType)...)
+
+	return append(message, protoVarint(7, counter)...)
+}
+
+// Builds the export URI of a `MigrationPayload` with the accounts, in the given QR code of the batch
+func migrationURI(accounts [][]byte, batchSize, batchIndex, batchID uint64) string {
+	var payload []byte
+
+	for _, account := range accounts {
+		payload = append(payload, protoBytes(1, account)...)
+	}
+
+	payload = append(payload, protoVarint(2, 1)...)
+	payload = append(payload, protoVarint(3, batchSize)...)
+	payload = append(payload, protoVarint(4, batchIndex)...)
+	payload = append(payload, protoVarint(5, batchID)...)
+
+	return "otpauth-migration://offline?data=" + url.QueryEscape(base64.StdEncoding.EncodeToString(payload))
+}
+
+// RFC 4226 secret, and its base32 form
+var (
+	migrationSecret       = []byte("12345678901234567890")
+	migrationSecretBase32 = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+)
+
+func TestParseGoogleMigration(t *testing.T) {
+	uri := migrationURI([][]byte{
+		otpParameters(migrationSecret, "GitHub:me@example.com", "GitHub", 1, 1, 2, 0),
+		otpParameters(migrationSecret, "alice", "Bank", 2, 2, 1, 42),
+		otpParameters(migrationSecret, "Legacy:bob", "", 0, 0, 0, 0),
+		otpParameters(migrationSecret, "weird", "Unknown", 1, 1, 9, 0),
+		otpParameters(migrationSecret, "odd", "Hash", 7, 1, 2, 0),
+	}, 3, 1, 1234)
+
+	part, err := ParseGoogleMigration(uri, "Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := MigrationPart{
+		Import: Import{
+			Entries: []ImportEntry{
+				{Folder: "Work", Token: Token{Type: TokenTypeTOTP, Issuer: "GitHub", Account: "me@example.com", Secret: migrationSecretBase32, Period: 30, Digits: 6, HashingAlgorithm: otp.AlgorithmSHA1}},
+				{Folder: "Work", Token: Token{Type: TokenTypeHOTP, Issuer: "Bank", Account: "alice", Secret: migrationSecretBase32, Period: 30, Digits: 8, InitialCounter: 42, HashingAlgorithm: otp.AlgorithmSHA256}},
+				{Folder: "Work", Token: Token{Type: TokenTypeTOTP, Issuer: "Legacy", Account: "bob", Secret: migrationSecretBase32, Period: 30, Digits: 6, HashingAlgorithm: otp.AlgorithmSHA1}},
+			},
+			Skipped: []SkippedEntry{
+				{Name: "Unknown:weird", Reason: "unsupported token type 9"},
+				{Name: "Hash:odd", Reason: "unsupported algorithm 7"},
+			},
+		},
+		BatchID:    1234,
+		BatchIndex: 1,
+		BatchSize:  3,
+	}
+
+	if !reflect.DeepEqual(part, want) {
+		t.Errorf("\n got %+v\nwant %+v", part, want)
+	}
+}
+
+func TestParseGoogleMigrationInvalid(t *testing.T) {
+	account := otpParameters(migrationSecret, "me", "GitHub", 1, 1, 2, 0)
+
+	tests := []struct {
+		name string
+		uri  string
+	}{
+		{"not an export", "otpauth://totp/GitHub:me?secret=" + migrationSecretBase32},
+		{"no data", "otpauth-migration://offline"},
+		{"not base64", "otpauth-migration://offline?data=not-base64!"},
+		{"truncated payload", "otpauth-migration://offline?data=" + url.QueryEscape(base64.StdEncoding.EncodeToString(protoBytes(1, account)[:10]))},
+		{"index past the batch", migrationURI([][]byte{account}, 2, 2, 1)},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseGoogleMigration(test.uri, "Work"); !errors.Is(err, ERR_MIGRATION_INVALID) {
+			t.Errorf("%s: got %v, want %v", test.name, err, ERR_MIGRATION_INVALID)
+		}
+	}
+}
+
+func TestParseQRCodesBatch(t *testing.T) {
+	first := migrationURI([][]byte{otpParameters(migrationSecret, "one", "A", 1, 1, 2, 0)}, 2, 0, 7)
+	second := migrationURI([][]byte{otpParameters(migrationSecret, "two", "B", 1, 1, 2, 0), otpParameters(migrationSecret, "three", "C", 1, 1, 2, 0)}, 2, 1, 7)
+
+	// Every QR code of the batch on the same image, one of them twice, along with a single token
+	imported := ParseQRCodes([]string{first, second, first, "otpauth://totp/D:four?secret=" + migrationSecretBase32, "https://example.com"}, "Work")
+
+	accounts := make([]string, 0)
+
+	for _, entry := range imported.Entries {
+		accounts = append(accounts, entry.Token.Account)
+	}
+
+	if want := []string{"one", "two", "three", "four"}; !reflect.DeepEqual(accounts, want) {
+		t.Errorf("got accounts %v, want %v", accounts, want)
+	}
+
+	if want := []SkippedEntry{{Name: "QR code 5", Reason: "not an otpauth:// URI"}}; !reflect.DeepEqual(imported.Skipped, want) {
+		t.Errorf("got skipped %+v, want %+v", imported.Skipped, want)
+	}
+}
@@ -0,0 +1,203 @@
+package tlockvault
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Token read from an export of another app, along with the folder it goes to
+type ImportEntry struct {
+	// Folder the token goes to, it is created if it does not exist
+	Folder string
+
+	// Token
+	Token Token
+}
+
+// Entry of an export that cannot be imported
+type SkippedEntry struct {
+	// Name of the entry, as `issuer:account`
+	Name string
+
+	// Why it was skipped
+	Reason string
+}
+
+// Tokens read from an export of another app
+type Import struct {
+	// Tokens that can be imported
+	Entries []ImportEntry
+
+	// Entries that cannot be imported
+	Skipped []SkippedEntry
+}
+
+// What importing will do, or has done, to the vault
+type ImportSummary struct {
+	// Folders that are created
+	NewFolders []string
+
+	// Tokens that are added
+	Added []ImportEntry
+
+	// Tokens that are already in the vault, and are left out
+	Duplicates []ImportEntry
+
+	// Entries that cannot be imported
+	Skipped []SkippedEntry
+}
+
+// Returns the name of the entry, as `issuer:account`
+func entryName(issuer, account string) string {
+	return strings.Trim(issuer+":"+account, ":")
+}
+
+// Adds the entry to the import if the secret is usable, otherwise it is skipped
+func (imported *Import) add(folder string, token Token) {
+	token.Secret = strings.TrimSpace(token.Secret)
+
+	// mOTP secrets are hex, the others are base32
+	if token.Type == TokenTypeMOTP {
+		token.Secret = strings.ToLower(token.Secret)
+	} else {
+		token.Secret = strings.ToUpper(token.Secret)
+	}
+
+	if token.Secret == "" || !validSecret(token.Type, token.Secret) {
+		imported.skip(token.Issuer, token.Account, "invalid secret")
+		return
+	}
+
+	// Same defaults as otpauth:// URIs
+	if token.Period <= 0 {
+		token.Period = 30
+	}
+
+	if token.Digits <= 0 {
+		token.Digits = 6
+	}
+
+	imported.Entries = append(imported.Entries, ImportEntry{Folder: folder, Token: token})
+}
+
+// Marks the entry as skipped
+func (imported *Import) skip(issuer, account, reason string) {
+	imported.Skipped = append(imported.Skipped, SkippedEntry{Name: entryName(issuer, account), Reason: reason})
+}
+
+// Checks if the tokens are the same account, they may differ in their ID and counters
+func sameAccount(a, b Token) bool {
+	return a.Type == b.Type && a.Secret == b.Secret && a.Issuer == b.Issuer && a.Account == b.Account
+}
+
+// Checks if the vault already has the token, in any folder
+func (vault *Vault) hasAccount(token Token) bool {
+	for _, folder := range vault.Folders {
+		if slices.ContainsFunc(folder.Tokens, func(existing Token) bool { return sameAccount(existing, token) }) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Works out what importing would do to the vault, without changing it
+// Tokens already in the vault are left out, so the same export can be imported again
+func (vault *Vault) PlanImport(imported Import) ImportSummary {
+	summary := ImportSummary{Skipped: imported.Skipped}
+
+	// Tokens planned so far, to catch duplicates inside of the export itself
+	planned := make([]Token, 0)
+
+	for _, entry := range imported.Entries {
+		isPlanned := slices.ContainsFunc(planned, func(token Token) bool { return sameAccount(token, entry.Token) })
+
+		if isPlanned || vault.hasAccount(entry.Token) {
+			summary.Duplicates = append(summary.Duplicates, entry)
+			continue
+		}
+
+		// New folder
+		if !vault.folderExists(entry.Folder) && !slices.Contains(summary.NewFolders, entry.Folder) {
+			summary.NewFolders = append(summary.NewFolders, entry.Folder)
+		}
+
+		planned = append(planned, entry.Token)
+		summary.Added = append(summary.Added, entry)
+	}
+
+	return summary
+}
+
+// Imports the tokens into the vault, creating the folders that don't exist yet
+func (vault *Vault) ApplyImport(imported Import) (ImportSummary, error) {
+	if err := vault.writable(); err != nil {
+		return ImportSummary{}, err
+	}
+
+	summary := vault.PlanImport(imported)
+
+	// Create folders
+	for _, name := range summary.NewFolders {
+		vault.Folders = append(vault.Folders, Folder{Name: name})
+	}
+
+	// Add tokens, each one gets a new ID
+	for index, entry := range summary.Added {
+		folder := vault.findFolder(entry.Folder)
+
+		summary.Added[index].Token.ID = newTokenID()
+		vault.Folders[folder].Tokens = append(vault.Folders[folder].Tokens, summary.Added[index].Token)
+	}
+
+	// Write
+	if len(summary.Added) != 0 {
+		return summary, vault.write()
+	}
+
+	return summary, nil
+}
+
+// Reads the tokens from the contents of scanned QR codes into the given folder
+// The QR codes can be single otpauth:// URIs or Google Authenticator exports, the same QR code is only read once
+func ParseQRCodes(contents []string, folder string) Import {
+	var imported Import
+
+	scanned := make(map[string]bool)
+
+	for index, content := range contents {
+		content = strings.TrimSpace(content)
+
+		if scanned[content] {
+			continue
+		}
+
+		scanned[content] = true
+
+		// Google Authenticator export, with many accounts
+		if IsMigrationURI(content) {
+			part, err := ParseGoogleMigration(content, folder)
+			if err != nil {
+				imported.skip("", "Google Authenticator export", err.Error())
+				continue
+			}
+
+			imported.Entries = append(imported.Entries, part.Entries...)
+			imported.Skipped = append(imported.Skipped, part.Skipped...)
+
+			continue
+		}
+
+		// Named by its position, the content might carry a secret
+		token, err := TokenFromURI(content)
+		if err != nil {
+			imported.skip("", fmt.Sprintf("QR code %d", index+1), "not an otpauth:// URI")
+			continue
+		}
+
+		imported.add(folder, token)
+	}
+
+	return imported
+}
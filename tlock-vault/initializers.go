@@ -4,8 +4,6 @@ import (
 	"errors"
 	"os"
 	"path"
-
-	"github.com/kelindar/binary"
 )
 
 // Error represents the vault may be been moved or deleted
@@ -14,18 +12,34 @@ var ERR_VAULT_DELETED = errors.New("The vault does not exist, did you delete it?
 // Error represents that the password is invalid
 var ERR_PASSWORD_INVALID = errors.New("Wrong password, please try again")
 
+// Error represents that the vault file is truncated, tampered with or otherwise damaged
+var ERR_VAULT_CORRUPT = errors.New("The vault file is damaged and cannot be read, restore it from a backup")
+
 // Initializes a new instance of the vault at the given path
 func Initialize(at, password string) (*Vault, error) {
 	// Log if there was error while creating
-	if err := os.MkdirAll(path.Dir(at), os.ModePerm); err != nil {
+	if err := os.MkdirAll(path.Dir(at), 0o700); err != nil {
+		return nil, err
+	}
+
+	// Generate keys
+	key, err := newSessionKey(password)
+	if err != nil {
 		return nil, err
 	}
 
 	// Initialize vault
 	vault := Vault{
 		path:     at,
-		password: password,
-		dataChan: make(chan []Folder, 1),
+		key:      key,
+		dataChan: make(chan pendingWrite, 1),
+		errChan:  make(chan error, 1),
+		writer:   newWriterState(),
+	}
+
+	// Lock
+	if err := vault.lock(); err != nil {
+		return nil, err
 	}
 
 	// Run post init hook
@@ -38,13 +52,26 @@ func Initialize(at, password string) (*Vault, error) {
 	return &vault, nil
 }
 
-// Loads a new vault instance
 // Loads a vault instance from the given path
 func Load(path, password string) (*Vault, error) {
+	return load(path, password, true)
+}
+
+// Loads a vault instance from the given path without taking its lock
+// The vault is read-only, for processes that only read the tokens while another instance may be editing them
+func LoadReadOnly(path, password string) (*Vault, error) {
+	return load(path, password, false)
+}
+
+// Loads a vault instance from the given path, locking it if asked to
+func load(path, password string, lock bool) (*Vault, error) {
 	// Raw data
 	var raw []byte
 	var decrypted []byte
 
+	// Keys
+	var key *sessionKey
+
 	// Any error
 	var err error
 
@@ -53,25 +80,57 @@ func Load(path, password string) (*Vault, error) {
 		return nil, ERR_VAULT_DELETED
 	}
 
-	// Empty data
+	// Folders
 	var data []Folder
 
 	// Decrypt
-	if decrypted, err = Decrypt(password, raw); err != nil {
+	if key, decrypted, err = unlock(password, raw); err != nil {
+		// Format errors are not because of the password
+		if errors.Is(err, ERR_VAULT_UNSUPPORTED) || errors.Is(err, ERR_VAULT_CORRUPT) {
+			return nil, err
+		}
+
 		return nil, ERR_PASSWORD_INVALID
 	}
 
-	// Unmarshal binary serialized data
-	if err := binary.Unmarshal(decrypted, &data); err != nil {
-		return nil, ERR_PASSWORD_INVALID
+	// Unmarshal binary serialized data, migrating it to the current schema
+	// We were able to decrypt it, so any failure here is not because of the password
+	if data, err = decodePayload(isLegacyFormat(raw), decrypted); err != nil {
+		return nil, err
+	}
+
+	// Vaults from older formats have no data key, generate one
+	// They are upgraded to the current format on the next write
+	if key == nil {
+		if key, err = newSessionKey(password); err != nil {
+			return nil, err
+		}
 	}
 
-	// Create vault instance and return
+	return newLoadedVault(path, raw, key, data, lock)
+}
+
+// Creates the vault instance for the decrypted contents of the vault file
+func newLoadedVault(path string, raw []byte, key *sessionKey, data []Folder, lock bool) (*Vault, error) {
 	vault := &Vault{
 		path:     path,
 		Folders:  data,
-		password: password,
-		dataChan: make(chan []Folder, 1),
+		key:      key,
+		dataChan: make(chan pendingWrite, 1),
+		errChan:  make(chan error, 1),
+		writer:   newWriterState(),
+	}
+
+	// We know what is on the disk
+	vault.setKnownContents(raw)
+
+	// Lock, or fallback to read-only mode if another instance is using the vault
+	if lock {
+		if err := vault.lock(); err != nil {
+			return nil, err
+		}
+	} else {
+		vault.readOnly = true
 	}
 
 	// Run post init hook
@@ -0,0 +1,146 @@
+package tlockvault
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Writes the vault file and loads it with the password
+func loadFile(t *testing.T, contents []byte, password string) (*Vault, error) {
+	path := filepath.Join(t.TempDir(), "vault.dat")
+
+	if err := os.WriteFile(path, contents, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	vault, err := LoadReadOnly(path, password)
+	if err == nil {
+		t.Cleanup(func() { vault.Close() })
+	}
+
+	return vault, err
+}
+
+// Checks that the vault holds the token of `tokenV1Fields` in the folder "Work"
+func checkMigratedVault(t *testing.T, name string, vault *Vault) {
+	tokens := vault.GetTokens("Work")
+
+	if len(vault.Folders) != 1 || len(tokens) != 1 {
+		t.Fatalf("%s: got folders %+v", name, vault.Folders)
+	}
+
+	if token := tokens[0]; token.ID == "" || token.Secret != tokenV1Fields.Secret || token.Account != tokenV1Fields.Account || token.UsageCounter != tokenV1Fields.UsageCounter {
+		t.Errorf("%s: got token %+v", name, token)
+	}
+}
+
+func TestLoadLegacyFormat(t *testing.T) {
+	payload := legacyPayload(t, []folderV1{{Name: "Work", Tokens: []tokenV1{tokenV1Fields}}})
+
+	// Layout: nonce | ciphertext | salt
+	key, salt, err := GenerateKey("password", nil, legacyKDFParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := seal(key, payload, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := append(sealed, salt...)
+
+	vault, err := loadFile(t, legacy, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkMigratedVault(t, "legacy", vault)
+
+	// Wrong password
+	if _, err := loadFile(t, legacy, "wrong"); !errors.Is(err, ERR_PASSWORD_INVALID) {
+		t.Errorf("wrong password: got %v, want %v", err, ERR_PASSWORD_INVALID)
+	}
+
+	// Too short to hold the salt
+	if _, err := loadFile(t, legacy[:SALT_SIZE-1], "password"); !errors.Is(err, ERR_VAULT_CORRUPT) {
+		t.Errorf("truncated: got %v, want %v", err, ERR_VAULT_CORRUPT)
+	}
+}
+
+func TestLoadVersion1(t *testing.T) {
+	payload := payloadAt(t, 1, []folderV1{{Name: "Work", Tokens: []tokenV1{tokenV1Fields}}})
+
+	// The payload is encrypted with the key derived from the password, bound to the header
+	key, salt, err := GenerateKey("password", nil, DefaultKDFParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := Header{Version: 1, KDF: DefaultKDFParams(), Salt: salt}.Marshal()
+
+	sealed, err := seal(key, payload, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vault, err := loadFile(t, append(header, sealed...), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkMigratedVault(t, "version 1", vault)
+}
+
+func TestLoadCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.dat")
+
+	vault, err := Initialize(path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vault.AddFolder("Work")
+	vault.Close()
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headerLength := len(vault.key.header.Marshal())
+
+	// Flips a byte of the contents
+	flipped := func(index int) []byte {
+		changed := bytes.Clone(contents)
+		changed[index] ^= 0xff
+
+		return changed
+	}
+
+	tests := []struct {
+		name     string
+		contents []byte
+	}{
+		{"empty", []byte{}},
+		{"truncated in the magic", contents[:4]},
+		{"truncated in the header", contents[:headerLength/2]},
+		{"truncated after the header", contents[:headerLength]},
+		{"truncated in the payload", contents[:len(contents)-1]},
+		{"damaged payload", flipped(len(contents) - 1)},
+	}
+
+	for _, test := range tests {
+		if _, err := loadFile(t, test.contents, "password"); !errors.Is(err, ERR_VAULT_CORRUPT) {
+			t.Errorf("%s: got %v, want %v", test.name, err, ERR_VAULT_CORRUPT)
+		}
+	}
+
+	// A damaged header is indistinguishable from a wrong password, as it is bound to the wrapped key
+	if _, err := loadFile(t, flipped(headerLength-1), "password"); !errors.Is(err, ERR_PASSWORD_INVALID) {
+		t.Errorf("damaged header: got %v, want %v", err, ERR_PASSWORD_INVALID)
+	}
+}
@@ -0,0 +1,145 @@
+package tlockvault
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/eklairs/tlock/tlock-internal/utils"
+)
+
+// Error representing that the vault is opened in read-only mode
+var ERR_VAULT_READ_ONLY = errors.New("The vault is open in another tlock instance, changes will not be saved")
+
+// Name of the lock file inside of the vault directory
+const LOCK_FILE_NAME = "vault.lock"
+
+// Locks held by this process
+// Multiple vault instances in the same process share the lock, so that only other processes are kept out
+var heldLocks = struct {
+	sync.Mutex
+	locks map[string]*heldLock
+}{locks: make(map[string]*heldLock)}
+
+// A lock held by this process
+type heldLock struct {
+	// Lock file
+	file *os.File
+
+	// Number of vaults using the lock
+	refs int
+}
+
+// Returns the path to the lock file for the vault at the given path
+func lockPathFor(vaultPath string) string {
+	return filepath.Join(filepath.Dir(vaultPath), LOCK_FILE_NAME)
+}
+
+// Tries to take the lock for the vault at the given path
+// It returns false if another process holds the lock
+func acquireLock(vaultPath string) (bool, error) {
+	heldLocks.Lock()
+	defer heldLocks.Unlock()
+
+	lockPath := lockPathFor(vaultPath)
+
+	// We already own it
+	if lock, ok := heldLocks.locks[lockPath]; ok {
+		lock.refs++
+
+		return true, nil
+	}
+
+	// Lock
+	file, err := utils.LockFile(lockPath)
+
+	if errors.Is(err, utils.FILE_LOCKED_ERR) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	heldLocks.locks[lockPath] = &heldLock{file: file, refs: 1}
+
+	return true, nil
+}
+
+// Releases the lock for the vault at the given path
+func releaseLock(vaultPath string) {
+	heldLocks.Lock()
+	defer heldLocks.Unlock()
+
+	lockPath := lockPathFor(vaultPath)
+
+	if lock, ok := heldLocks.locks[lockPath]; ok {
+		if lock.refs--; lock.refs == 0 {
+			lock.file.Close()
+
+			delete(heldLocks.locks, lockPath)
+		}
+	}
+}
+
+// Takes the lock for the vault, or marks it as read-only if another instance holds it
+func (vault *Vault) lock() error {
+	locked, err := acquireLock(vault.path)
+
+	vault.locked = locked
+	vault.readOnly = !locked
+
+	return err
+}
+
+// Releases the lock of the vault, if it holds one
+func (vault *Vault) unlock() {
+	if vault.locked {
+		releaseLock(vault.path)
+
+		vault.locked = false
+	}
+}
+
+// Returns if the vault is opened in read-only mode because another tlock instance is using it
+func (vault Vault) ReadOnly() bool {
+	return vault.readOnly
+}
+
+// Takes the lock of a vault that was opened in read-only mode, so that it can be written
+// It fails with `ERR_VAULT_READ_ONLY` while another tlock instance holds the lock
+func (vault *Vault) AcquireLock() error {
+	if vault.remote != nil {
+		return ERR_VAULT_REMOTE
+	}
+
+	if vault.locked {
+		return nil
+	}
+
+	locked, err := acquireLock(vault.path)
+	if err != nil {
+		return err
+	}
+
+	if !locked {
+		return ERR_VAULT_READ_ONLY
+	}
+
+	vault.locked = true
+	vault.readOnly = false
+
+	return nil
+}
+
+// Flushes the pending writes and gives up the lock taken with `AcquireLock`
+// The vault is in read-only mode again afterwards
+func (vault *Vault) ReleaseLock() error {
+	err := vault.Flush()
+
+	vault.unlock()
+	vault.readOnly = true
+
+	return err
+}
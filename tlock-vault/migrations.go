@@ -0,0 +1,280 @@
+package tlockvault
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	kelindar "github.com/kelindar/binary"
+	"github.com/pquerna/otp"
+)
+
+// Schema version of the payload written by this version of tlock
+// Bump this and add a migration whenever the layout of `Folder` or `Token` changes
+const SCHEMA_VERSION = 4
+
+// Schema version of the payloads inside legacy vault files
+const SCHEMA_LEGACY = 0
+
+// Size of the schema version prefix in the payload
+const schemaPrefixSize = 2
+
+// A migration upgrades a serialized payload by exactly one schema version
+type migration func(payload []byte) ([]byte, error)
+
+// Migrations, where the migration at index `i` upgrades the payload from version `i` to `i+1`
+var migrations = []migration{
+	migrateV0ToV1,
+	migrateV1ToV2,
+	migrateV2ToV3,
+	migrateV3ToV4,
+}
+
+// Serializes the folders into a payload prefixed with the schema version
+func encodePayload(folders []Folder) ([]byte, error) {
+	serialized, err := kelindar.Marshal(folders)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefix schema
+	payload := binary.BigEndian.AppendUint16(make([]byte, 0, schemaPrefixSize+len(serialized)), SCHEMA_VERSION)
+
+	// Return
+	return append(payload, serialized...), nil
+}
+
+// Deserializes the payload, running any migrations required to reach the current schema
+// Legacy vault files carry an unprefixed payload of the legacy schema
+func decodePayload(legacy bool, payload []byte) ([]Folder, error) {
+	var folders []Folder
+	var err error
+
+	// Schema version
+	schema := SCHEMA_LEGACY
+
+	if !legacy {
+		if len(payload) < schemaPrefixSize {
+			return nil, ERR_VAULT_CORRUPT
+		}
+
+		schema, payload = int(binary.BigEndian.Uint16(payload)), payload[schemaPrefixSize:]
+	}
+
+	// Payloads from the future cannot be read
+	if schema > SCHEMA_VERSION {
+		return nil, ERR_VAULT_UNSUPPORTED
+	}
+
+	// Upgrade step by step
+	for ; schema < SCHEMA_VERSION; schema++ {
+		if payload, err = migrations[schema](payload); err != nil {
+			return nil, fmt.Errorf("%w (migrating from schema %d: %s)", ERR_VAULT_CORRUPT, schema, err)
+		}
+	}
+
+	// Deserialize
+	if err = kelindar.Unmarshal(payload, &folders); err != nil {
+		return nil, ERR_VAULT_CORRUPT
+	}
+
+	return folders, nil
+}
+
+// == Migrations ==
+
+// Schema 1 only introduced the schema prefix, the layout of the folders is unchanged
+func migrateV0ToV1(payload []byte) ([]byte, error) {
+	return payload, nil
+}
+
+// Layout of a token in schema 1
+// Frozen, so that the migration keeps working when `Token` changes
+type tokenV1 struct {
+	Type             TokenType
+	Issuer           string
+	Account          string
+	Secret           string
+	InitialCounter   int
+	Period           int
+	Digits           int
+	HashingAlgorithm otp.Algorithm
+	UsageCounter     int
+}
+
+// Layout of a folder in schema 1
+type folderV1 struct {
+	Name   string
+	Tokens []tokenV1
+}
+
+// Layout of a token in schema 2
+type tokenV2 struct {
+	ID               string
+	Type             TokenType
+	Issuer           string
+	Account          string
+	Secret           string
+	InitialCounter   int
+	Period           int
+	Digits           int
+	HashingAlgorithm otp.Algorithm
+	UsageCounter     int
+}
+
+// Layout of a folder in schema 2
+type folderV2 struct {
+	Name   string
+	Tokens []tokenV2
+}
+
+// Schema 2 added a stable ID to every token, existing tokens get a freshly generated one
+func migrateV1ToV2(payload []byte) ([]byte, error) {
+	var folders []folderV1
+
+	if err := kelindar.Unmarshal(payload, &folders); err != nil {
+		return nil, err
+	}
+
+	// Upgrade
+	upgraded := make([]folderV2, len(folders))
+
+	for i, folder := range folders {
+		upgraded[i] = folderV2{Name: folder.Name, Tokens: make([]tokenV2, len(folder.Tokens))}
+
+		for j, token := range folder.Tokens {
+			upgraded[i].Tokens[j] = tokenV2{
+				ID:               newTokenID(),
+				Type:             token.Type,
+				Issuer:           token.Issuer,
+				Account:          token.Account,
+				Secret:           token.Secret,
+				InitialCounter:   token.InitialCounter,
+				Period:           token.Period,
+				Digits:           token.Digits,
+				HashingAlgorithm: token.HashingAlgorithm,
+				UsageCounter:     token.UsageCounter,
+			}
+		}
+	}
+
+	return kelindar.Marshal(upgraded)
+}
+
+// Layout of a parameter of an otpauth:// URI in schema 3
+type uriParameterV3 struct {
+	Key   string
+	Value string
+}
+
+// Layout of a token in schema 3
+type tokenV3 struct {
+	ID               string
+	Type             TokenType
+	Issuer           string
+	Account          string
+	Secret           string
+	InitialCounter   int
+	Period           int
+	Digits           int
+	HashingAlgorithm otp.Algorithm
+	UsageCounter     int
+	Image            string
+	Parameters       []uriParameterV3
+}
+
+// Layout of a folder in schema 3
+type folderV3 struct {
+	Name   string
+	Tokens []tokenV3
+}
+
+// Schema 3 added the image and the unknown parameters of the otpauth:// URI, existing tokens have none
+func migrateV2ToV3(payload []byte) ([]byte, error) {
+	var folders []folderV2
+
+	if err := kelindar.Unmarshal(payload, &folders); err != nil {
+		return nil, err
+	}
+
+	// Upgrade
+	upgraded := make([]folderV3, len(folders))
+
+	for i, folder := range folders {
+		upgraded[i] = folderV3{Name: folder.Name, Tokens: make([]tokenV3, len(folder.Tokens))}
+
+		for j, token := range folder.Tokens {
+			upgraded[i].Tokens[j] = tokenV3{
+				ID:               token.ID,
+				Type:             token.Type,
+				Issuer:           token.Issuer,
+				Account:          token.Account,
+				Secret:           token.Secret,
+				InitialCounter:   token.InitialCounter,
+				Period:           token.Period,
+				Digits:           token.Digits,
+				HashingAlgorithm: token.HashingAlgorithm,
+				UsageCounter:     token.UsageCounter,
+			}
+		}
+	}
+
+	return kelindar.Marshal(upgraded)
+}
+
+// Layout of a token in schema 4
+type tokenV4 struct {
+	ID               string
+	Type             TokenType
+	Issuer           string
+	Account          string
+	Secret           string
+	InitialCounter   int
+	Period           int
+	Digits           int
+	HashingAlgorithm otp.Algorithm
+	UsageCounter     int
+	PIN              string
+	Image            string
+	Parameters       []uriParameterV3
+}
+
+// Layout of a folder in schema 4
+type folderV4 struct {
+	Name   string
+	Tokens []tokenV4
+}
+
+// Schema 4 added the PIN of Yandex.Key and mOTP tokens, existing tokens have none
+func migrateV3ToV4(payload []byte) ([]byte, error) {
+	var folders []folderV3
+
+	if err := kelindar.Unmarshal(payload, &folders); err != nil {
+		return nil, err
+	}
+
+	// Upgrade
+	upgraded := make([]folderV4, len(folders))
+
+	for i, folder := range folders {
+		upgraded[i] = folderV4{Name: folder.Name, Tokens: make([]tokenV4, len(folder.Tokens))}
+
+		for j, token := range folder.Tokens {
+			upgraded[i].Tokens[j] = tokenV4{
+				ID:               token.ID,
+				Type:             token.Type,
+				Issuer:           token.Issuer,
+				Account:          token.Account,
+				Secret:           token.Secret,
+				InitialCounter:   token.InitialCounter,
+				Period:           token.Period,
+				Digits:           token.Digits,
+				HashingAlgorithm: token.HashingAlgorithm,
+				UsageCounter:     token.UsageCounter,
+				Image:            token.Image,
+				Parameters:       token.Parameters,
+			}
+		}
+	}
+
+	return kelindar.Marshal(upgraded)
+}
@@ -0,0 +1,135 @@
+package tlockvault
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	kelindar "github.com/kelindar/binary"
+	"github.com/pquerna/otp"
+)
+
+// Serializes the folders of an old schema, prefixed with its version
+func payloadAt(t *testing.T, schema uint16, folders any) []byte {
+	serialized, err := kelindar.Marshal(folders)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return append(binary.BigEndian.AppendUint16(nil, schema), serialized...)
+}
+
+// Serializes the folders of the legacy schema, which had no prefix
+func legacyPayload(t *testing.T, folders []folderV1) []byte {
+	serialized, err := kelindar.Marshal(folders)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return serialized
+}
+
+// Fields that every schema has
+var tokenV1Fields = tokenV1{
+	Type:             TokenTypeHOTP,
+	Issuer:           "GitHub",
+	Account:          "me@example.com",
+	Secret:           "JBSWY3DPEHPK3PXP",
+	InitialCounter:   3,
+	Period:           30,
+	Digits:           8,
+	HashingAlgorithm: otp.AlgorithmSHA256,
+	UsageCounter:     2,
+}
+
+func TestDecodePayloadMigrates(t *testing.T) {
+	v1 := tokenV1Fields
+
+	v2 := tokenV2{ID: "token-id", Type: v1.Type, Issuer: v1.Issuer, Account: v1.Account, Secret: v1.Secret, InitialCounter: v1.InitialCounter, Period: v1.Period, Digits: v1.Digits, HashingAlgorithm: v1.HashingAlgorithm, UsageCounter: v1.UsageCounter}
+
+	v3 := tokenV3{ID: v2.ID, Type: v2.Type, Issuer: v2.Issuer, Account: v2.Account, Secret: v2.Secret, InitialCounter: v2.InitialCounter, Period: v2.Period, Digits: v2.Digits, HashingAlgorithm: v2.HashingAlgorithm, UsageCounter: v2.UsageCounter, Image: "https://example.com/icon.png", Parameters: []uriParameterV3{{Key: "color", Value: "blue"}}}
+
+	v4 := tokenV4{ID: v3.ID, Type: TokenTypeMOTP, Issuer: v3.Issuer, Account: v3.Account, Secret: "0123456789abcdef", InitialCounter: v3.InitialCounter, Period: 10, Digits: 6, HashingAlgorithm: v3.HashingAlgorithm, UsageCounter: v3.UsageCounter, PIN: "1234", Image: v3.Image, Parameters: v3.Parameters}
+
+	// What every schema migrates to, the fields that it did not have are empty
+	fromV1 := Token{Type: v1.Type, Issuer: v1.Issuer, Account: v1.Account, Secret: v1.Secret, InitialCounter: v1.InitialCounter, Period: v1.Period, Digits: v1.Digits, HashingAlgorithm: v1.HashingAlgorithm, UsageCounter: v1.UsageCounter}
+
+	fromV2 := fromV1
+	fromV2.ID = v2.ID
+
+	fromV3 := fromV2
+	fromV3.Image = v3.Image
+	fromV3.Parameters = []URIParameter{{Key: "color", Value: "blue"}}
+
+	fromV4 := fromV3
+	fromV4.Type, fromV4.Secret, fromV4.Period, fromV4.Digits, fromV4.PIN = v4.Type, v4.Secret, v4.Period, v4.Digits, v4.PIN
+
+	current, err := encodePayload([]Folder{{Name: "Work", Tokens: []Token{fromV4}}, {Name: "Empty"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		legacy  bool
+		payload []byte
+		want    Token
+	}{
+		{"schema 0", true, legacyPayload(t, []folderV1{{Name: "Work", Tokens: []tokenV1{v1}}, {Name: "Empty"}}), fromV1},
+		{"schema 1", false, payloadAt(t, 1, []folderV1{{Name: "Work", Tokens: []tokenV1{v1}}, {Name: "Empty"}}), fromV1},
+		{"schema 2", false, payloadAt(t, 2, []folderV2{{Name: "Work", Tokens: []tokenV2{v2}}, {Name: "Empty"}}), fromV2},
+		{"schema 3", false, payloadAt(t, 3, []folderV3{{Name: "Work", Tokens: []tokenV3{v3}}, {Name: "Empty"}}), fromV3},
+		{"schema 4", false, payloadAt(t, 4, []folderV4{{Name: "Work", Tokens: []tokenV4{v4}}, {Name: "Empty"}}), fromV4},
+		{"current schema", false, current, fromV4},
+	}
+
+	for _, test := range tests {
+		folders, err := decodePayload(test.legacy, test.payload)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if len(folders) != 2 || folders[0].Name != "Work" || folders[1].Name != "Empty" || len(folders[0].Tokens) != 1 || len(folders[1].Tokens) != 0 {
+			t.Errorf("%s: got folders %+v", test.name, folders)
+			continue
+		}
+
+		got := folders[0].Tokens[0]
+
+		// Tokens from before schema 2 get a new ID
+		if test.want.ID == "" {
+			if got.ID == "" {
+				t.Errorf("%s: token has no ID", test.name)
+			}
+
+			got.ID = ""
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDecodePayloadRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		legacy  bool
+		payload []byte
+		want    error
+	}{
+		{"newer schema", false, payloadAt(t, SCHEMA_VERSION+1, []Folder{}), ERR_VAULT_UNSUPPORTED},
+		{"no schema prefix", false, []byte{0}, ERR_VAULT_CORRUPT},
+		{"garbage", false, append(binary.BigEndian.AppendUint16(nil, SCHEMA_VERSION), 0xff, 0xff, 0xff), ERR_VAULT_CORRUPT},
+		{"garbage in an old schema", false, append(binary.BigEndian.AppendUint16(nil, 1), 0xff, 0xff, 0xff), ERR_VAULT_CORRUPT},
+		{"garbage in the legacy schema", true, []byte{0xff, 0xff, 0xff}, ERR_VAULT_CORRUPT},
+	}
+
+	for _, test := range tests {
+		if _, err := decodePayload(test.legacy, test.payload); !errors.Is(err, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package tlockvault
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pquerna/otp"
+)
+
+// Error representing that the URI is not an otpauth:// URI
+var ERR_URI_INVALID = errors.New("Not a valid otpauth:// URI")
+
+// Error representing that the token type of the URI is not supported
+var ERR_URI_TYPE = errors.New("Unsupported token type, only totp, hotp, steam, yaotp and motp URIs are supported")
+
+// Error representing that the URI has no secret
+var ERR_URI_NO_SECRET = errors.New("The otpauth:// URI has no secret")
+
+// Scheme of the token URIs
+const otpauthScheme = "otpauth"
+
+// Token types by the host of the URI
+var uriTypes = map[string]TokenType{
+	"totp":  TokenTypeTOTP,
+	"hotp":  TokenTypeHOTP,
+	"steam": TokenTypeSteam,
+	"yaotp": TokenTypeYandex,
+	"motp":  TokenTypeMOTP,
+}
+
+// Hashing algorithms by their name in the URI
+var uriAlgorithms = map[string]otp.Algorithm{
+	"SHA1":   otp.AlgorithmSHA1,
+	"SHA256": otp.AlgorithmSHA256,
+	"SHA512": otp.AlgorithmSHA512,
+	"MD5":    otp.AlgorithmMD5,
+}
+
+// Parameter of an otpauth:// URI that tlock does not use itself
+// They are kept with the token, so that the URIs written by the exports carry them
+type URIParameter struct {
+	// Name of the parameter
+	Key string
+
+	// Value, unescaped
+	Value string
+}
+
+// Splits the raw query into its parameters, keeping their order
+func splitQuery(rawQuery string) ([]URIParameter, error) {
+	params := make([]URIParameter, 0)
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(pair, "=")
+
+		key, err := url.QueryUnescape(key)
+		if err != nil {
+			return nil, ERR_URI_INVALID
+		}
+
+		if value, err = url.QueryUnescape(value); err != nil {
+			return nil, ERR_URI_INVALID
+		}
+
+		params = append(params, URIParameter{Key: key, Value: value})
+	}
+
+	return params, nil
+}
+
+// Parses a positive integer parameter
+func parseURIInt(key, value string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("%w, invalid %s %q", ERR_URI_INVALID, key, value)
+	}
+
+	return number, nil
+}
+
+// Parses a token from its otpauth:// URI, as in the Google Authenticator key URI format
+//
+//	otpauth://TYPE/ISSUER:ACCOUNT?secret=SECRET&issuer=ISSUER&algorithm=SHA1&digits=6&period=30&counter=0&image=URL
+//
+// The issuer parameter takes precedence over the one in the label, parameters that tlock does not use are kept
+// Steam Guard tokens are read from both `otpauth://steam/` and `otpauth://totp/` with `encoder=steam`
+// Yandex.Key and mOTP tokens carry their PIN in the `pin` parameter
+func TokenFromURI(uri string) (Token, error) {
+	parsed, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || !strings.EqualFold(parsed.Scheme, otpauthScheme) {
+		return Token{}, ERR_URI_INVALID
+	}
+
+	// Type
+	tokenType, ok := uriTypes[strings.ToLower(parsed.Host)]
+	if !ok {
+		return Token{}, ERR_URI_TYPE
+	}
+
+	token := Token{
+		Type:             tokenType,
+		Period:           30,
+		Digits:           6,
+		HashingAlgorithm: otp.AlgorithmSHA1,
+	}
+
+	// Label is `issuer:account`, or only the account
+	label := strings.TrimPrefix(parsed.Path, "/")
+
+	if issuer, account, found := strings.Cut(label, ":"); found {
+		token.Issuer = strings.TrimSpace(issuer)
+		token.Account = strings.TrimSpace(account)
+	} else {
+		token.Account = strings.TrimSpace(label)
+	}
+
+	// Parameters
+	params, err := splitQuery(parsed.RawQuery)
+	if err != nil {
+		return Token{}, err
+	}
+
+	// Known parameters are only read once, repeated ones are kept as they are
+	seen := make(map[string]bool)
+
+	for _, param := range params {
+		key := strings.ToLower(param.Key)
+
+		if seen[key] {
+			token.Parameters = append(token.Parameters, param)
+			continue
+		}
+
+		seen[key] = true
+
+		switch key {
+		case "secret":
+			token.Secret = strings.ReplaceAll(param.Value, " ", "")
+
+			// mOTP secrets are hex, the others are base32
+			if tokenType == TokenTypeMOTP {
+				token.Secret = strings.ToLower(token.Secret)
+			} else {
+				token.Secret = strings.ToUpper(token.Secret)
+			}
+
+		case "issuer":
+			if issuer := strings.TrimSpace(param.Value); issuer != "" {
+				token.Issuer = issuer
+			}
+
+		case "algorithm":
+			algorithm, ok := uriAlgorithms[strings.ToUpper(param.Value)]
+			if !ok {
+				return Token{}, fmt.Errorf("%w, unsupported algorithm %q", ERR_URI_INVALID, param.Value)
+			}
+
+			token.HashingAlgorithm = algorithm
+
+		case "digits":
+			if token.Digits, err = parseURIInt(key, param.Value); err != nil {
+				return Token{}, err
+			}
+
+		case "period":
+			if token.Period, err = parseURIInt(key, param.Value); err != nil {
+				return Token{}, err
+			}
+
+		case "counter":
+			if token.InitialCounter, err = parseURIInt(key, param.Value); err != nil {
+				return Token{}, err
+			}
+
+		case "image":
+			token.Image = param.Value
+
+		case "pin":
+			if !tokenType.HasPIN() {
+				token.Parameters = append(token.Parameters, param)
+				break
+			}
+
+			token.PIN = param.Value
+
+		case "encoder":
+			// Steam Guard tokens are also written as TOTP URIs with `encoder=steam`
+			if !strings.EqualFold(param.Value, "steam") {
+				token.Parameters = append(token.Parameters, param)
+				break
+			}
+
+			token.Type = TokenTypeSteam
+
+		default:
+			token.Parameters = append(token.Parameters, param)
+		}
+	}
+
+	if token.Secret == "" {
+		return Token{}, ERR_URI_NO_SECRET
+	}
+
+	// Zero would make the codes unusable, use the defaults
+	if token.Period == 0 {
+		token.Period = 30
+	}
+
+	if token.Digits == 0 {
+		token.Digits = 6
+	}
+
+	// Steam Guard, Yandex.Key and mOTP codes cannot be customized
+	token.Normalize()
+
+	return token, nil
+}
+
+// Escapes a value for the URI, spaces are written as `%20` which all the apps read
+func escapeURIValue(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// Returns the otpauth:// URI of the token, that TokenFromURI reads back into the same token
+// HOTP tokens carry their current counter
+func (token Token) URI() string {
+	var query strings.Builder
+
+	// Appends a parameter
+	add := func(key, value string) {
+		if query.Len() != 0 {
+			query.WriteString("&")
+		}
+
+		query.WriteString(escapeURIValue(key) + "=" + escapeURIValue(value))
+	}
+
+	add("secret", token.Secret)
+
+	if token.Issuer != "" {
+		add("issuer", token.Issuer)
+	}
+
+	add("algorithm", token.HashingAlgorithm.String())
+	add("digits", strconv.Itoa(token.Digits))
+
+	// Type specific
+	if token.Type == TokenTypeHOTP {
+		add("counter", strconv.Itoa(token.Counter()))
+	} else {
+		add("period", strconv.Itoa(token.Period))
+	}
+
+	if token.Type.HasPIN() {
+		add("pin", token.PIN)
+	}
+
+	if token.Image != "" {
+		add("image", token.Image)
+	}
+
+	for _, param := range token.Parameters {
+		add(param.Key, param.Value)
+	}
+
+	// Label is `issuer:account`
+	label := escapeURIValue(token.Account)
+
+	if token.Issuer != "" {
+		label = escapeURIValue(token.Issuer) + ":" + label
+	}
+
+	return fmt.Sprintf("%s://%s/%s?%s", otpauthScheme, token.Type, label, query.String())
+}
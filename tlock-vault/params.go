@@ -0,0 +1,35 @@
+package tlockvault
+
+import "github.com/pquerna/otp"
+
+// Fixed parameters of the token types that cannot be customized
+const (
+	// Steam Guard codes are always 5 characters, from a 30 seconds SHA1 TOTP
+	steamDigits = 5
+	steamPeriod = 30
+
+	// Yandex.Key codes are always 8 characters, from a 30 seconds SHA256 TOTP
+	yandexDigits = 8
+	yandexPeriod = 30
+
+	// mOTP codes are always 6 hex characters that change every 10 seconds
+	motpDigits = 6
+	motpPeriod = 10
+)
+
+// Returns the current counter of a HOTP token
+func (token Token) Counter() int {
+	return token.InitialCounter + token.UsageCounter
+}
+
+// Sets the fixed digits, period and hashing algorithm of the token types that cannot be customized
+func (token *Token) Normalize() {
+	switch token.Type {
+	case TokenTypeSteam:
+		token.Digits, token.Period, token.HashingAlgorithm = steamDigits, steamPeriod, otp.AlgorithmSHA1
+	case TokenTypeYandex:
+		token.Digits, token.Period, token.HashingAlgorithm = yandexDigits, yandexPeriod, otp.AlgorithmSHA256
+	case TokenTypeMOTP:
+		token.Digits, token.Period, token.HashingAlgorithm = motpDigits, motpPeriod, otp.AlgorithmMD5
+	}
+}
@@ -0,0 +1,74 @@
+package tlockvault
+
+import (
+	"errors"
+	"os"
+)
+
+// Error representing that the vault is unlocked by another program, which only gives out the codes
+var ERR_VAULT_REMOTE = errors.New("The vault is unlocked by the agent, log in with the password to make changes")
+
+// Program that holds the vault unlocked and serves it, like the agent
+// The tokens it gives out have no secrets, so it generates their codes as well
+type Remote interface {
+	// Returns the folders, without the secrets of the tokens
+	List() ([]Folder, error)
+
+	// Returns the current code of the token and the seconds before it changes
+	Code(id string) (string, int, error)
+}
+
+// Opens the vault that the remote holds unlocked, without knowing the password
+// The vault cannot be changed, and the codes of its tokens have to be asked from the remote
+func LoadRemote(path string, remote Remote) (*Vault, error) {
+	// Read folders
+	folders, err := remote.List()
+	if err != nil {
+		return nil, err
+	}
+
+	vault := &Vault{
+		path:     path,
+		Folders:  folders,
+		remote:   remote,
+		dataChan: make(chan pendingWrite, 1),
+		errChan:  make(chan error, 1),
+		writer:   newWriterState(),
+	}
+
+	// Notice the changes made by the other instances
+	vault.readKnownContents()
+
+	// Run post init hook
+	vault.PostInit()
+
+	// Return
+	return vault, nil
+}
+
+// Returns the remote that serves the vault, or nil if the vault was unlocked with the password
+func (vault Vault) Remote() Remote {
+	return vault.remote
+}
+
+// Remembers the current contents of the vault file as known
+// The writer lock must be held
+func (vault *Vault) readKnownContents() {
+	if contents, err := os.ReadFile(vault.path); err == nil {
+		vault.setKnownContents(contents)
+	}
+}
+
+// Replaces the folders with the ones that the remote serves
+// The writer lock must be held
+func (vault *Vault) reloadRemote() error {
+	folders, err := vault.remote.List()
+	if err != nil {
+		return err
+	}
+
+	vault.Folders = folders
+	vault.readKnownContents()
+
+	return nil
+}
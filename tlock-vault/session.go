@@ -0,0 +1,140 @@
+package tlockvault
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+)
+
+// Error representing that the vault on the disk is encrypted with a different key than the unlocked one
+var ERR_VAULT_KEY_CHANGED = errors.New("The vault on the disk was re-encrypted by another program, please log in again")
+
+// Keys of an unlocked vault
+// The payload is encrypted with a random data key, which is wrapped by a key derived from the password
+// This way the password is only needed to unlock the vault, and the writes don't have to run the KDF
+type sessionKey struct {
+	// Random key that encrypts the payload
+	dataKey []byte
+
+	// Header of the vault, carries the KDF params, salt and the wrapped data key
+	header Header
+}
+
+// Generates a new random data key and wraps it with the password
+func newSessionKey(password string) (*sessionKey, error) {
+	dataKey := make([]byte, KEY_SIZE)
+
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	return wrapDataKey(password, dataKey)
+}
+
+// Wraps the data key with a key derived from the password
+func wrapDataKey(password string, dataKey []byte) (*sessionKey, error) {
+	params := DefaultKDFParams()
+
+	// Derive key encryption key
+	kek, salt, err := GenerateKey(password, nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header, without the wrapped key yet
+	header := Header{
+		Version: VAULT_FORMAT_VERSION,
+		KDF:     params,
+		Salt:    salt,
+	}
+
+	// Wrap, binding the KDF params and the salt
+	if header.WrappedKey, err = seal(kek, dataKey, header.marshalKDF()); err != nil {
+		return nil, err
+	}
+
+	return &sessionKey{dataKey: dataKey, header: header}, nil
+}
+
+// Unwraps the data key from the header with the password
+func unwrapDataKey(password string, header Header) (*sessionKey, error) {
+	// Derive key encryption key
+	kek, _, err := GenerateKey(password, header.Salt, header.KDF)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unwrap
+	dataKey, err := open(kek, header.WrappedKey, header.marshalKDF())
+	if err != nil {
+		return nil, err
+	}
+
+	return &sessionKey{dataKey: dataKey, header: header}, nil
+}
+
+// Checks if the password unlocks the vault, used to confirm sensitive actions
+// It runs the KDF, so it takes as long as unlocking the vault does
+func (vault *Vault) VerifyPassword(password string) bool {
+	// Vaults served by a remote have no key to compare with
+	if vault.remote != nil {
+		return false
+	}
+
+	vault.writer.lock.Lock()
+	header, dataKey := vault.key.header, vault.key.dataKey
+	vault.writer.lock.Unlock()
+
+	key, err := unwrapDataKey(password, header)
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(key.dataKey, dataKey) == 1
+}
+
+// Returns a session key with the same data key, wrapped with the new password
+// Changing the password does not re-encrypt the payload with a new key
+func (key *sessionKey) rewrap(password string) (*sessionKey, error) {
+	return wrapDataKey(password, key.dataKey)
+}
+
+// Encrypts the payload into a vault container
+func (key *sessionKey) seal(plaintext []byte) ([]byte, error) {
+	header := key.header.Marshal()
+
+	// Encrypt
+	encrypted, err := seal(key.dataKey, plaintext, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header, encrypted...), nil
+}
+
+// Decrypts a version 2 vault container with the data key
+// It returns the session key with the header of the container, which might have been rewrapped by someone else
+func (key *sessionKey) open(data []byte) (*sessionKey, []byte, error) {
+	// Only version 2 containers share our data key
+	if isLegacyFormat(data) {
+		return nil, nil, ERR_VAULT_KEY_CHANGED
+	}
+
+	// Parse header
+	header, headerRaw, data, err := parseHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if header.Version < 2 {
+		return nil, nil, ERR_VAULT_KEY_CHANGED
+	}
+
+	// Decrypt
+	plaintext, err := open(key.dataKey, data, headerRaw)
+	if err != nil {
+		return nil, nil, ERR_VAULT_KEY_CHANGED
+	}
+
+	return &sessionKey{dataKey: key.dataKey, header: header}, plaintext, nil
+}
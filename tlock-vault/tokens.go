@@ -1,62 +1,60 @@
 package tlockvault
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"slices"
 
 	"github.com/eklairs/tlock/tlock-internal/utils"
-	"github.com/pquerna/otp"
 )
 
-// Converts `totp` or `hotp` to TokenType
-func toType(type_ string) TokenType {
-	if type_ == "hotp" {
-		return TokenTypeHOTP
+// Adds a new token to the given folder from token URI
+func (vault *Vault) AddToken(folder string, uri string) error {
+	token, err := TokenFromURI(uri)
+	if err != nil {
+		return err
 	}
 
-	return TokenTypeTOTP
+	// Add
+	return vault.AddTokenFromToken(folder, token)
 }
 
-// Adds a new token to the given folder from token URI
-func (vault *Vault) AddToken(folder string, uri string) error {
-	// Key
-	var key *otp.Key
-	var err error
-
-	// Generate key
-	if key, err = otp.NewKeyFromURL(uri); err == nil {
-		// Generate token
-		token := Token{
-			Type:             toType(key.Type()),
-			Issuer:           key.Issuer(),
-			Account:          key.AccountName(),
-			Secret:           key.Secret(),
-			InitialCounter:   0,
-			Period:           int(key.Period()),
-			Digits:           key.Digits().Length(),
-			HashingAlgorithm: key.Algorithm(),
-			UsageCounter:     0,
-		}
+// Generates a new random token ID
+func newTokenID() string {
+	id := make([]byte, 16)
 
-		// Add
-		return vault.AddTokenFromToken(folder, token)
-	}
+	// Reading from crypto/rand does not fail on any supported platform
+	rand.Read(id)
 
-	// Return
-	return err
+	return hex.EncodeToString(id)
 }
 
 // Adds a new token to the given folder
+// The token is assigned a new ID, unless it already has one
 func (vault *Vault) AddTokenFromToken(folder string, token Token) error {
 	var err error
 
-	if token.Secret, err = vault.ValidateToken(token.Secret); err == nil {
-		// Find folder and if it exists, add
-		if index := vault.findFolder(folder); index != -1 {
-			vault.Folders[index].Tokens = append(vault.Folders[index].Tokens, token)
+	if err = vault.writable(); err != nil {
+		return err
+	}
+
+	if token.ID == "" {
+		token.ID = newTokenID()
+	}
+
+	if token.Secret, err = vault.ValidateToken(token.Type, token.Secret); err == nil {
+		// Find folder
+		index := vault.findFolder(folder)
+
+		if index == -1 {
+			return ERR_FOLDER_NOT_FOUND
 		}
 
+		// Add
+		vault.Folders[index].Tokens = append(vault.Folders[index].Tokens, token)
+
 		// Write
-		vault.write()
+		err = vault.write()
 	}
 
 	// Return
@@ -64,20 +62,25 @@ func (vault *Vault) AddTokenFromToken(folder string, token Token) error {
 }
 
 // Replace a token in the given folder
-func (vault *Vault) ReplaceToken(fromFolder string, token, newToken Token) error {
+// The new token keeps the ID of the replaced one
+func (vault *Vault) ReplaceToken(fromFolder string, id string, newToken Token) error {
 	var err error
 
-	if newToken.Secret, err = vault.ValidateToken(newToken.Secret); token.Secret == newToken.Secret || err == nil {
-		// Get folder index
-		if index := vault.findFolder(fromFolder); index != -1 {
+	if err = vault.writable(); err != nil {
+		return err
+	}
+
+	if newToken.Secret, err = vault.ValidateToken(newToken.Type, newToken.Secret); err == nil {
+		// Find the token
+		if folder, token := vault.locateToken(fromFolder, id); folder != -1 && token != -1 {
+			// Keep the ID
+			newToken.ID = id
+
 			// Replace
-			vault.Folders[index].Tokens[vault.findToken(index, token.Secret)] = newToken
+			vault.Folders[folder].Tokens[token] = newToken
 
 			// Write
-			vault.write()
-
-			// Ok!
-			return nil
+			err = vault.write()
 		}
 	}
 
@@ -85,89 +88,132 @@ func (vault *Vault) ReplaceToken(fromFolder string, token, newToken Token) error
 }
 
 // Deletes a token in the given folder
-func (vault *Vault) DeleteToken(folder string, token Token) {
+func (vault *Vault) DeleteToken(folder string, id string) error {
+	if err := vault.writable(); err != nil {
+		return err
+	}
+
 	// Find the folder
-	if folder, token := vault.locateToken(folder, token); folder != -1 && token != -1 {
+	if folder, token := vault.locateToken(folder, id); folder != -1 && token != -1 {
 		vault.Folders[folder].Tokens = utils.Remove(vault.Folders[folder].Tokens, token)
 	}
 
 	// Write
-	vault.write()
+	return vault.write()
 }
 
 // Move a token to the given folder
-func (vault *Vault) MoveToken(token Token, fromFolder, toFolder string) {
-	// Remove from existing
-	vault.DeleteToken(fromFolder, token)
+// The token is moved as it is, it was validated when it was added
+func (vault *Vault) MoveToken(id string, fromFolder, toFolder string) error {
+	if err := vault.writable(); err != nil {
+		return err
+	}
+
+	// Both ends must exist, so that the token cannot get lost
+	target := vault.findFolder(toFolder)
+
+	if folder, token := vault.locateToken(fromFolder, id); folder != -1 && token != -1 && target != -1 {
+		// Keep it around, it retains its ID
+		moved := vault.Folders[folder].Tokens[token]
 
-	// Add to the new one
-	vault.AddTokenFromToken(toFolder, token)
+		// Remove from existing
+		vault.Folders[folder].Tokens = utils.Remove(vault.Folders[folder].Tokens, token)
+
+		// Add to the new one
+		vault.Folders[target].Tokens = append(vault.Folders[target].Tokens, moved)
+
+		// Write
+		return vault.write()
+	}
+
+	return nil
 }
 
-// Move a token to the given folder
-func (vault *Vault) IncreaseCounter(folder string, token Token) {
+// Increases the usage counter of a HOTP token
+func (vault *Vault) IncreaseCounter(folder string, id string) error {
+	if err := vault.writable(); err != nil {
+		return err
+	}
+
 	// Find the folder
-	if folder, token := vault.locateToken(folder, token); folder != -1 && token != -1 {
+	if folder, token := vault.locateToken(folder, id); folder != -1 && token != -1 {
 		vault.Folders[folder].Tokens[token].UsageCounter++
 	}
 
 	// Write
-	vault.write()
+	return vault.write()
+}
+
+// Sets the current counter of a HOTP token, keeping its initial counter
+func (vault *Vault) SetCounter(folder string, id string, counter int) error {
+	if err := vault.writable(); err != nil {
+		return err
+	}
+
+	// Find the folder
+	if folder, token := vault.locateToken(folder, id); folder != -1 && token != -1 {
+		vault.Folders[folder].Tokens[token].UsageCounter = counter - vault.Folders[folder].Tokens[token].InitialCounter
+	}
+
+	// Write
+	return vault.write()
 }
 
 // Moves the token down
-func (vault *Vault) MoveTokenDown(folder string, token Token) bool {
+func (vault *Vault) MoveTokenDown(folder string, id string) (bool, error) {
+	if err := vault.writable(); err != nil {
+		return false, err
+	}
+
 	// Find
-	if folder, token := vault.locateToken(folder, token); folder != -1 && token != -1 {
+	if folder, token := vault.locateToken(folder, id); folder != -1 && token != -1 {
 		// If it is already at the bottom, skip
 		if token == len(vault.Folders[folder].Tokens)-1 {
-			return false
+			return false, nil
 		}
 
 		// Swapppp
 		vault.Folders[folder].Tokens = utils.Swap(vault.Folders[folder].Tokens, token, token+1)
 
 		// Wrap
-		vault.write()
-
-		// Return
-		return true
+		return true, vault.write()
 	}
 
-	return false
+	return false, nil
 }
 
 // Moves the token up
-func (vault *Vault) MoveTokenUp(folder string, token Token) bool {
+func (vault *Vault) MoveTokenUp(folder string, id string) (bool, error) {
+	if err := vault.writable(); err != nil {
+		return false, err
+	}
+
 	// Find
-	if folder, token := vault.locateToken(folder, token); folder != -1 && token != -1 {
+	if folder, token := vault.locateToken(folder, id); folder != -1 && token != -1 {
 		// If it is already at the bottom, skip
 		if token == 0 {
-			return false
+			return false, nil
 		}
 
 		// Swapppp
 		vault.Folders[folder].Tokens = utils.Swap(vault.Folders[folder].Tokens, token, token-1)
 
 		// Wrap
-		vault.write()
-
-		// Return
-		return true
+		return true, vault.write()
 	}
 
-	return false
+	return false, nil
 }
 
-// Find a token index by its secret
-func (vault *Vault) findToken(folder int, secret string) int {
-	return slices.IndexFunc(vault.Folders[folder].Tokens, func(token Token) bool { return token.Secret == secret })
+// Find a token index by its ID
+func (vault *Vault) findToken(folder int, id string) int {
+	return slices.IndexFunc(vault.Folders[folder].Tokens, func(token Token) bool { return token.ID == id })
 }
 
-// Checks if the token exists with a secret
-func (vault *Vault) tokenExists(secret string) bool {
+// Checks if the token with the ID exists in any of the folders
+func (vault *Vault) tokenExists(id string) bool {
 	for i := 0; i < len(vault.Folders); i++ {
-		if vault.findToken(i, secret) != -1 {
+		if vault.findToken(i, id) != -1 {
 			return true
 		}
 	}
@@ -176,10 +222,15 @@ func (vault *Vault) tokenExists(secret string) bool {
 }
 
 // Finds the index of folder as well token
-func (vault *Vault) locateToken(folder string, token Token) (int, int) {
+func (vault *Vault) locateToken(folder string, id string) (int, int) {
 	// Find folder index
 	folderIndex := vault.findFolder(folder)
 
+	// Folder does not exist
+	if folderIndex == -1 {
+		return -1, -1
+	}
+
 	// Return
-	return folderIndex, vault.findToken(folderIndex, token.Secret)
+	return folderIndex, vault.findToken(folderIndex, id)
 }
@@ -6,13 +6,45 @@ import "github.com/pquerna/otp"
 const (
 	TokenTypeTOTP = iota
 	TokenTypeHOTP
+	TokenTypeSteam
+	TokenTypeYandex
+	TokenTypeMOTP
 )
 
 // Token Type
 type TokenType int
 
+// Checks if the codes of the tokens change with time, rather than with a counter
+func (tokenType TokenType) TimeBased() bool {
+	return tokenType != TokenTypeHOTP
+}
+
+// Name of the type, as in the otpauth:// URIs
+func (tokenType TokenType) String() string {
+	switch tokenType {
+	case TokenTypeHOTP:
+		return "hotp"
+	case TokenTypeSteam:
+		return "steam"
+	case TokenTypeYandex:
+		return "yaotp"
+	case TokenTypeMOTP:
+		return "motp"
+	}
+
+	return "totp"
+}
+
+// Checks if the codes of the tokens are generated with a PIN
+func (tokenType TokenType) HasPIN() bool {
+	return tokenType == TokenTypeYandex || tokenType == TokenTypeMOTP
+}
+
 // Token
 type Token struct {
+	// Unique identifier of the token
+	ID string
+
 	// Type
 	Type TokenType
 
@@ -39,6 +71,15 @@ type Token struct {
 
 	// Usage counter [only in case of HOTP based tokens]
 	UsageCounter int
+
+	// PIN mixed into the codes [only in case of Yandex.Key and mOTP tokens]
+	PIN string
+
+	// URL of the image of the issuer, from the otpauth:// URI
+	Image string
+
+	// Parameters of the otpauth:// URI that tlock does not use, kept for the exports
+	Parameters []URIParameter
 }
 
 // Folder
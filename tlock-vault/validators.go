@@ -1,6 +1,7 @@
 package tlockvault
 
 import (
+	"encoding/hex"
 	"errors"
 	"strings"
 
@@ -13,15 +14,15 @@ var ERR_FOLDER_EMPTY = errors.New("Folder name cannot be empty")
 // Error representing that the folder with that name already exists
 var ERR_FOLDER_EXISTS = errors.New("Folder with that name already exists")
 
+// Error representing that the folder does not exist
+var ERR_FOLDER_NOT_FOUND = errors.New("Folder with that name does not exist")
+
 // Error representing that the token secret is empty
 var ERR_TOKEN_EMPTY = errors.New("Secret value cannot be empty")
 
 // Error representing that the secret is invalid
 var ERR_TOKEN_INVALID = errors.New("Secret is invalid, are you sure it is typed correctly?")
 
-// Error representing that the secret already exists
-var ERR_TOKEN_EXISTS = errors.New("Token with that secret already exists")
-
 // Validates if the folder name is fit to be used
 func (vault Vault) validateFolderName(name string) (string, error) {
 	// Sanitize by trimming off the spaces
@@ -43,8 +44,8 @@ func (vault Vault) validateFolderName(name string) (string, error) {
 
 // Validates if the token is fit to be used
 // It is checked on the basis of the fact that it can be used to generate a secret
-// And no other token with the same secret exist
-func (vault Vault) ValidateToken(secret string) (string, error) {
+// Tokens are identified by their ID, so multiple tokens can share the same secret
+func (vault Vault) ValidateToken(tokenType TokenType, secret string) (string, error) {
 	// Sanitize by trimming off the spaces
 	secret = strings.TrimSpace(secret)
 
@@ -53,16 +54,28 @@ func (vault Vault) ValidateToken(secret string) (string, error) {
 		return secret, ERR_TOKEN_EMPTY
 	}
 
-	// Check if the folder already exists
-	if vault.tokenExists(secret) {
-		return secret, ERR_TOKEN_EXISTS
+	// mOTP secrets are stored in lower case hex
+	if tokenType == TokenTypeMOTP {
+		secret = strings.ToLower(secret)
 	}
 
 	// Try to generate token
-	if !utils.ValidateSecret(secret) {
+	if !validSecret(tokenType, secret) {
 		return secret, ERR_TOKEN_INVALID
 	}
 
 	// Return
 	return secret, nil
 }
+
+// Checks if the secret is in the encoding of the token type
+// mOTP secrets are hex, the secrets of the other types are base32
+func validSecret(tokenType TokenType, secret string) bool {
+	if tokenType == TokenTypeMOTP {
+		_, err := hex.DecodeString(secret)
+
+		return err == nil
+	}
+
+	return utils.ValidateSecret(secret)
+}
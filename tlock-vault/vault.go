@@ -8,15 +8,92 @@ type Vault struct {
 	// Path to the file
 	path string
 
-	// Password
-	password string
+	// Keys of the unlocked vault
+	// The password itself is not kept around
+	key *sessionKey
 
 	// Channel to send the data to be written
-	dataChan chan []Folder
+	dataChan chan pendingWrite
+
+	// State of the writer
+	writer *writerState
+
+	// Whether this instance holds the cross-process lock of the vault
+	locked bool
+
+	// Whether the vault is opened in read-only mode, because another tlock instance holds the lock
+	readOnly bool
+
+	// Program that serves the vault, if it was not unlocked with the password
+	remote Remote
+
+	// Channel on which the errors from the writer are reported
+	errChan chan error
+}
+
+// Returns the channel on which the errors while writing the vault are reported
+func (vault Vault) WriteErrors() <-chan error {
+	return vault.errChan
+}
+
+// Reports the write error without blocking the writer
+// If an error is already pending, the new one is dropped
+func (vault Vault) reportError(err error) {
+	select {
+	case vault.errChan <- err:
+	default:
+	}
+}
+
+// Returns why the vault cannot be changed, if it cannot
+// Every change checks this first, so that nothing is changed in memory that cannot be saved
+func (vault Vault) writable() error {
+	// Only the codes are served
+	if vault.remote != nil {
+		return ERR_VAULT_REMOTE
+	}
+
+	// Another instance owns the vault
+	if vault.readOnly {
+		return ERR_VAULT_READ_ONLY
+	}
+
+	// The key and the folders are wiped
+	if vault.writer.forgotten.Load() {
+		return ERR_VAULT_FORGOTTEN
+	}
+
+	return nil
 }
 
 // Sends the data to be written to the channel
-func (vault Vault) write() {
+// Writes that cannot happen are reported and returned
+func (vault Vault) write() error {
+	// Writing a read-only vault would silently erase the changes of the instance that owns it
+	if err := vault.writable(); err != nil {
+		vault.reportError(err)
+
+		return err
+	}
+
+	data := pendingWrite{
+		generation: vault.writer.queued.Add(1),
+		folders:    vault.Folders,
+	}
+
+	// The worker is gone once the vault is closed, write it ourselves
+	if vault.writer.closed() {
+		vault.writer.lock.Lock()
+		defer vault.writer.lock.Unlock()
+
+		err := vault.commit(data)
+		if err != nil {
+			vault.reportError(err)
+		}
+
+		return err
+	}
+
 	// Clear any existing data
 	select {
 	case <-vault.dataChan:
@@ -24,20 +101,55 @@ func (vault Vault) write() {
 	}
 
 	// Send the new data to write
-	vault.dataChan <- vault.Folders
+	vault.dataChan <- data
+
+	return nil
 }
 
 // Updates the password for the vault
-func (vault *Vault) ChangePassword(password string) {
-	// Set the master password
-	vault.password = password
+// Only the data key is rewrapped, so the other instances that have the vault unlocked keep working
+// The payload is bound to the header, so it is sealed again with the same data key
+// The old key is kept if the vault cannot be rewritten, so that it keeps matching the file
+func (vault *Vault) ChangePassword(password string) error {
+	// The file would keep the old password
+	if err := vault.writable(); err != nil {
+		return err
+	}
+
+	// Rewrap
+	key, err := vault.key.rewrap(password)
+	if err != nil {
+		return err
+	}
+
+	// Swap the key, the writer might be using it
+	vault.writer.lock.Lock()
+	oldKey := vault.key
+	vault.key = key
+	vault.writer.lock.Unlock()
+
+	// Rewrite right away
+	if err = vault.write(); err == nil {
+		err = vault.Flush()
+	}
+
+	// Put back the key of the file
+	if err != nil {
+		vault.writer.lock.Lock()
+		vault.key = oldKey
+		vault.writer.lock.Unlock()
 
-	// Rewrite
-	vault.write()
+		return err
+	}
+
+	return nil
 }
 
 // Stuff to run after the vault is initialized
 func (vault *Vault) PostInit() {
+	// Track it so that it can be flushed on exit
+	register(vault)
+
 	// Start worker
 	go vault.startFileWriterWorker(vault.dataChan)
 }
@@ -1,29 +1,263 @@
 package tlockvault
 
 import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/eklairs/tlock/tlock-internal/utils"
-	"github.com/kelindar/binary"
 )
 
+// Error representing that the vault has been forgotten, its key is gone so nothing can be written anymore
+var ERR_VAULT_FORGOTTEN = errors.New("The vault has been locked, unlock it again to make changes")
+
+// Permissions of the vault file
+const VAULT_FILE_PERM = 0o600
+
+// Data queued to be written
+type pendingWrite struct {
+	// Generation of the data, increases with every queued write
+	generation uint64
+
+	// Folders to write
+	folders []Folder
+}
+
+// State shared between the writer worker and the flushes
+type writerState struct {
+	// Serializes the writes to the file
+	lock sync.Mutex
+
+	// Signalled after every write attempt
+	settledCond *sync.Cond
+
+	// Generation of the last queued data
+	queued atomic.Uint64
+
+	// Generation of the last data whose write was attempted
+	settled uint64
+
+	// Error of the last write attempt
+	lastErr error
+
+	// Fingerprint of the vault file as we last read or wrote it
+	disk fileFingerprint
+
+	// Closed when the vault is closed
+	done chan struct{}
+
+	// Makes closing idempotent
+	closeOnce sync.Once
+
+	// Set once the data key has been wiped
+	forgotten atomic.Bool
+}
+
+// Initializes a new writer state
+func newWriterState() *writerState {
+	state := &writerState{done: make(chan struct{})}
+	state.settledCond = sync.NewCond(&state.lock)
+
+	return state
+}
+
+// Returns if the vault has been closed
+func (state *writerState) closed() bool {
+	select {
+	case <-state.done:
+		return true
+	default:
+		return false
+	}
+}
+
 // Writing to file implementation
-func (vault *Vault) startFileWriterWorker(recv chan []Folder) {
+func (vault *Vault) startFileWriterWorker(recv chan pendingWrite) {
 	for {
-		if data, ok := <-recv; ok {
-			// Serialize
-			serialized, _ := binary.Marshal(data)
+		select {
+		case data := <-recv:
+			vault.writer.lock.Lock()
 
-			// Encrypt
-			encrypted, _ := Encrypt(vault.password, serialized)
-
-			// Create parent dir
-			if file, err := utils.EnsureExists(vault.path); err == nil {
-				file.Write(encrypted)
+			if err := vault.commit(data); err != nil {
+				vault.reportError(err)
 			}
+
+			vault.writer.lock.Unlock()
+
+		case <-vault.writer.done:
+			return
 		}
 
 		// Sleep for 1 second
 		time.Sleep(time.Second * 1)
 	}
 }
+
+// Writes the data unless a newer generation has already been written
+// The writer lock must be held
+func (vault *Vault) commit(data pendingWrite) error {
+	// The key is zeroed, sealing with it would destroy the vault
+	if vault.writer.forgotten.Load() {
+		return ERR_VAULT_FORGOTTEN
+	}
+
+	// Skip stale data
+	if data.generation <= vault.writer.settled {
+		return nil
+	}
+
+	// Write
+	err := vault.writeToFile(data.folders)
+
+	if err != nil {
+		err = fmt.Errorf("Failed to save the vault: %w", err)
+	}
+
+	// Mark as settled and wake up any flushes
+	vault.writer.settled = data.generation
+	vault.writer.lastErr = err
+	vault.writer.settledCond.Broadcast()
+
+	return err
+}
+
+// Synchronously writes any pending data to the disk
+// Returns the error of the last write, if it failed
+func (vault *Vault) Flush() error {
+	// Everything queued till now must be written before returning
+	target := vault.writer.queued.Load()
+
+	vault.writer.lock.Lock()
+	defer vault.writer.lock.Unlock()
+
+	// Write the pending data ourselves if the worker has not picked it up yet
+	select {
+	case data := <-vault.dataChan:
+		vault.commit(data)
+	default:
+	}
+
+	// Otherwise the worker has it in hand, wait for it to finish
+	for vault.writer.settled < target {
+		vault.writer.settledCond.Wait()
+	}
+
+	return vault.writer.lastErr
+}
+
+// Flushes any pending data and stops the writer
+// Writes made after closing are written synchronously
+func (vault *Vault) Close() error {
+	err := vault.Flush()
+
+	// Stop worker and release the lock
+	vault.writer.closeOnce.Do(func() {
+		close(vault.writer.done)
+		vault.unlock()
+	})
+
+	// Forget about it
+	unregister(vault)
+
+	return err
+}
+
+// Returns a channel that is closed once the vault is closed
+func (vault *Vault) Closed() <-chan struct{} {
+	return vault.writer.done
+}
+
+// Closes the vault and wipes the unlocked data key and folders from the memory, as far as Go allows
+// The vault cannot be used anymore afterwards
+func (vault *Vault) Forget() error {
+	err := vault.Close()
+
+	vault.writer.lock.Lock()
+	defer vault.writer.lock.Unlock()
+
+	vault.writer.forgotten.Store(true)
+	vault.Folders = nil
+
+	// Vaults served by a remote have no key
+	if vault.key != nil {
+		clear(vault.key.dataKey)
+	}
+
+	return err
+}
+
+// Serializes, encrypts and atomically writes the folders to the vault file
+// The writer lock must be held
+func (vault *Vault) writeToFile(data []Folder) error {
+	// Serialize
+	serialized, err := encodePayload(data)
+	if err != nil {
+		return err
+	}
+
+	// Encrypt
+	encrypted, err := vault.key.seal(serialized)
+	if err != nil {
+		return err
+	}
+
+	// Write
+	if err = utils.WriteFileAtomic(vault.path, encrypted, VAULT_FILE_PERM); err != nil {
+		return err
+	}
+
+	// We know what is on the disk now
+	vault.setKnownContents(encrypted)
+
+	return nil
+}
+
+// == Registry of open vaults ==
+
+// Vaults that are currently open
+var openVaults = struct {
+	sync.Mutex
+	vaults map[*Vault]struct{}
+}{vaults: make(map[*Vault]struct{})}
+
+// Registers the vault as open
+func register(vault *Vault) {
+	openVaults.Lock()
+	defer openVaults.Unlock()
+
+	openVaults.vaults[vault] = struct{}{}
+}
+
+// Removes the vault from the open vaults
+func unregister(vault *Vault) {
+	openVaults.Lock()
+	defer openVaults.Unlock()
+
+	delete(openVaults.vaults, vault)
+}
+
+// Closes every open vault, flushing their pending writes
+// Meant to be called before the process exits, it returns the errors of the writes that failed
+func CloseAll() error {
+	openVaults.Lock()
+	vaults := make([]*Vault, 0, len(openVaults.vaults))
+
+	for vault := range openVaults.vaults {
+		vaults = append(vaults, vault)
+	}
+
+	openVaults.Unlock()
+
+	// Close
+	errs := make([]error, 0)
+
+	for _, vault := range vaults {
+		if err := vault.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
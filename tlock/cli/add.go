@@ -0,0 +1,143 @@
+package tlockcli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/eklairs/tlock/tlock-internal/utils"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	"github.com/pquerna/otp"
+)
+
+// Error representing that neither or both of the token sources were given
+var ERR_ADD_SOURCE = errors.New("Give exactly one of --uri, --secret or --qr-image")
+
+// Error representing that the vault has no folders to add the token to
+var ERR_NO_FOLDERS = errors.New("The vault has no folders, create one first")
+
+// `tlock add`
+func addCommand(args []string, stdout io.Writer) (err error) {
+	var options commonOptions
+	var uri, secret, qrImage, issuer, account, folder string
+	var period, digits int
+
+	// Flags
+	flags := newFlagSet("add [flags]", "Adds a new token from its otpauth:// URI or from its secret, or the tokens from the QR codes in an image.")
+	options.register(flags)
+	flags.StringVar(&uri, "uri", "", "otpauth:// URI of the token")
+	flags.StringVar(&secret, "secret", "", "base32 secret of a TOTP token")
+	flags.StringVar(&qrImage, "qr-image", "", "PNG, JPEG or GIF image, every QR code in it is added")
+	flags.StringVar(&issuer, "issuer", "", "issuer of the token, when adding from a secret")
+	flags.StringVar(&account, "account", "", "account name of the token, when adding from a secret")
+	flags.IntVar(&period, "period", 30, "period of the token in seconds, when adding from a secret")
+	flags.IntVar(&digits, "digits", 6, "number of digits of the token, when adding from a secret")
+	flags.StringVar(&folder, "folder", "", "folder to add the token to, defaults to the first folder")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if countGiven(uri, secret, qrImage) != 1 {
+		return ERR_ADD_SOURCE
+	}
+
+	// Read the QR codes before touching the vault
+	var contents []string
+
+	if qrImage != "" {
+		if contents, err = utils.ReadTokensFromImageFile(qrImage); err != nil {
+			return err
+		}
+	}
+
+	// Build token
+	var token tlockvault.Token
+
+	if uri != "" {
+		if token, err = tlockvault.TokenFromURI(uri); err != nil {
+			return err
+		}
+	} else if secret != "" {
+		token = tlockvault.Token{
+			Type:             tlockvault.TokenTypeTOTP,
+			Issuer:           issuer,
+			Account:          account,
+			Secret:           secret,
+			Period:           period,
+			Digits:           digits,
+			HashingAlgorithm: otp.AlgorithmSHA1,
+		}
+	}
+
+	// Open vault
+	writer, err := options.openWriter()
+	if err != nil {
+		return err
+	}
+
+	// Closing flushes the write, so its error matters
+	defer func() {
+		if closeErr := writer.close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	// Default folder
+	if folder == "" {
+		if len(writer.folders) == 0 {
+			return ERR_NO_FOLDERS
+		}
+
+		folder = writer.folders[0].Name
+	}
+
+	// Every QR code of the image, tokens that are already in the vault are left out
+	if qrImage != "" {
+		if !slices.ContainsFunc(writer.folders, func(f tlockvault.Folder) bool { return f.Name == folder }) {
+			return tlockvault.ERR_FOLDER_NOT_FOUND
+		}
+
+		summary, err := writer.importTokens(tlockvault.ParseQRCodes(contents, folder), false)
+		if err != nil {
+			return err
+		}
+
+		output := toImportOutput(summary, false)
+
+		if options.json {
+			return writeJSON(stdout, output)
+		}
+
+		return printImportOutput(stdout, output)
+	}
+
+	// Add
+	added, err := writer.add(folder, token)
+	if err != nil {
+		return err
+	}
+
+	// Print
+	if options.json {
+		return writeJSON(stdout, toTokenOutput(folder, added))
+	}
+
+	_, err = fmt.Fprintf(stdout, "Added %s to %s\n", displayName(added), folder)
+
+	return err
+}
+
+// Returns how many of the values are not empty
+func countGiven(values ...string) int {
+	count := 0
+
+	for _, value := range values {
+		if value != "" {
+			count++
+		}
+	}
+
+	return count
+}
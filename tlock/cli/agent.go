@@ -0,0 +1,96 @@
+package tlockcli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	tlockagent "github.com/eklairs/tlock/tlock-agent"
+	"github.com/eklairs/tlock/tlock-internal/paths"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// `tlock agent`
+func agentCommand(args []string, stdout io.Writer) error {
+	var options commonOptions
+
+	// Flags
+	flags := newFlagSet(
+		"agent [flags]",
+		"Unlocks the vault and keeps it unlocked for the list, code, add and import commands, until it is locked with 'tlock lock'.\nThe agent runs in the foreground, start it in the background with your shell or service manager.",
+	)
+	options.registerUser(flags)
+	options.password.register(flags)
+	lifetime := flags.Duration("lifetime", 0, "lock the vault after this long, like 8h or 30m, 0 keeps it unlocked until it is locked")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// Find user
+	user, err := options.findUser()
+	if err != nil {
+		return err
+	}
+
+	// Unlock without taking the lock of the vault, so that the interface can still edit it
+	// The agent only takes the lock while it applies a change
+	vault, err := options.unlockVault(user, tlockvault.LoadReadOnly)
+	if err != nil {
+		return err
+	}
+
+	defer vault.Close()
+
+	// Listen
+	socket := paths.AgentSocketFor(user.S())
+
+	agent, err := tlockagent.Listen(vault, socket)
+	if err != nil {
+		return err
+	}
+
+	// Stop when asked to terminate
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	go func() {
+		<-signals
+		agent.Stop()
+	}()
+
+	fmt.Fprintf(os.Stderr, "Vault of %s unlocked, agent listening on %s\n", user.S(), socket)
+
+	// Serve
+	return agent.Serve(*lifetime)
+}
+
+// `tlock lock`
+func lockCommand(args []string, stdout io.Writer) error {
+	var options commonOptions
+
+	// Flags
+	flags := newFlagSet("lock [flags]", "Locks the vault by stopping the agent of the user.")
+	options.registerUser(flags)
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// Find user
+	user, err := options.findUser()
+	if err != nil {
+		return err
+	}
+
+	// Lock
+	if err := tlockagent.Lock(user.S()); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stdout, "Locked the vault of %s\n", user.S())
+
+	return err
+}
@@ -0,0 +1,344 @@
+package tlockcli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tlockagent "github.com/eklairs/tlock/tlock-agent"
+	tlockcore "github.com/eklairs/tlock/tlock-core"
+	tlockotp "github.com/eklairs/tlock/tlock-otp"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Error representing that there are no users
+var ERR_NO_USERS = errors.New("No users exist yet, create one by running tlock without any arguments")
+
+// Error representing that the user could not be determined
+var ERR_USER_REQUIRED = errors.New("Multiple users exist, pick one with --user")
+
+// Error representing that the user does not exist
+var ERR_USER_NOT_FOUND = errors.New("User with that name does not exist")
+
+// Usage of the CLI
+var usage = `Usage: tlock [command] [flags]
+
+Running tlock without a command starts the interactive interface.
+
+Commands:
+  list    List the tokens
+  code    Print the current code of a token
+  add     Add a new token
+  import  Import the tokens from another app or an export
+  export  Export the tokens
+  agent   Keep the vault unlocked for the other commands
+  lock    Lock the vault by stopping the agent
+
+Run 'tlock <command> --help' for the flags of a command.
+`
+
+// A subcommand
+type command func(args []string, stdout io.Writer) error
+
+// Subcommands
+var commands = map[string]command{
+	"list":   listCommand,
+	"code":   codeCommand,
+	"add":    addCommand,
+	"import": importCommand,
+	"export": exportCommand,
+	"agent":  agentCommand,
+	"lock":   lockCommand,
+}
+
+// Returns if the arguments should be handled by the CLI instead of the interactive interface
+func IsCommand(args []string) bool {
+	return len(args) > 0
+}
+
+// Runs the CLI with the arguments, without the program name
+// It returns the exit code of the process
+func Run(args []string) int {
+	// Help
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(os.Stdout, usage)
+		return 0
+	}
+
+	// Find command
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "tlock: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	// Run
+	if err := cmd(args[1:], os.Stdout); err != nil {
+		// Help was requested for the command, and it has already been printed
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+
+		// Flags were invalid, the flag set has already reported it
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+
+		fmt.Fprintf(os.Stderr, "tlock: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// Sentinel for flag parse errors, which are already reported by the flag set
+var errUsage = errors.New("usage")
+
+// Flags shared by all the commands
+type commonOptions struct {
+	// Name of the user
+	user string
+
+	// Password sources
+	password passwordOptions
+
+	// Whether to output JSON
+	json bool
+}
+
+// Creates a new flag set for the command
+func newFlagSet(name, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	// Usage
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: tlock %s\n\n%s\n\nFlags:\n", name, description)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+// Registers the common flags
+func (options *commonOptions) register(flags *flag.FlagSet) {
+	options.registerUser(flags)
+	flags.BoolVar(&options.json, "json", false, "print the output as JSON")
+	options.password.register(flags)
+}
+
+// Registers the flag for the user only, for the commands that don't print anything useful
+func (options *commonOptions) registerUser(flags *flag.FlagSet) {
+	flags.StringVar(&options.user, "user", "", "name of the user whose vault to use, optional if there is only one user")
+}
+
+// Parses the flags, mapping parse errors to `errUsage`
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return errUsage
+	}
+
+	return nil
+}
+
+// Finds the user to use
+func (options commonOptions) findUser() (tlockcore.User, error) {
+	// Users file does not exist if no user has been created yet
+	core, _ := tlockcore.New()
+
+	if len(core.Users) == 0 {
+		return "", ERR_NO_USERS
+	}
+
+	// Use the given user
+	if options.user != "" {
+		if !core.Exists(options.user) {
+			return "", fmt.Errorf("%w: %s", ERR_USER_NOT_FOUND, options.user)
+		}
+
+		return tlockcore.User(options.user), nil
+	}
+
+	// Only one user, so it is obvious which one to use
+	if len(core.Users) == 1 {
+		return core.Users[0], nil
+	}
+
+	return "", ERR_USER_REQUIRED
+}
+
+// Opens the vault of the user with the password, only for reading
+// The agent does not give out the secrets, so it cannot be used here
+// The vault is not locked, so it can be read while the interface or the agent has it open
+func (options commonOptions) openVault() (*tlockvault.Vault, error) {
+	// Find user
+	user, err := options.findUser()
+	if err != nil {
+		return nil, err
+	}
+
+	return options.unlockVault(user, tlockvault.LoadReadOnly)
+}
+
+// Unlocks the vault of the user with the password, using the given loader
+func (options commonOptions) unlockVault(user tlockcore.User, load func(path, password string) (*tlockvault.Vault, error)) (*tlockvault.Vault, error) {
+	// Get password from the given source
+	password, given, err := options.password.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if given {
+		return load(user.Vault(), password)
+	}
+
+	// Vaults can be created without a password, try that before prompting
+	if vault, err := load(user.Vault(), ""); err == nil {
+		return vault, nil
+	} else if !errors.Is(err, tlockvault.ERR_PASSWORD_INVALID) {
+		return nil, err
+	}
+
+	// Prompt
+	if password, err = promptPassword(fmt.Sprintf("Password for %s", user.S()), ERR_NO_PASSWORD); err != nil {
+		return nil, err
+	}
+
+	return load(user.Vault(), password)
+}
+
+// Folders of the user's vault along with a way to get the codes of the tokens
+type tokenSource struct {
+	// Folders, the secrets of the tokens are not available when served by the agent
+	folders []tlockvault.Folder
+
+	// Returns the current code of the token and the seconds before it changes
+	code func(token tlockvault.Token) (string, int, error)
+
+	// Releases the source
+	close func() error
+}
+
+// Opens the tokens of the user's vault, from the agent if it is running
+func (options commonOptions) openTokens() (tokenSource, error) {
+	// Find user
+	user, err := options.findUser()
+	if err != nil {
+		return tokenSource{}, err
+	}
+
+	// Served by the agent
+	if !options.password.given() {
+		if folders, err := tlockagent.List(user.S()); err == nil {
+			return tokenSource{
+				folders: folders,
+				code: func(token tlockvault.Token) (string, int, error) {
+					return tlockagent.Code(user.S(), token.ID)
+				},
+				close: func() error { return nil },
+			}, nil
+		}
+	}
+
+	// Open the vault ourselves, read-only so that a TUI started meanwhile can still write it
+	vault, err := options.unlockVault(user, tlockvault.LoadReadOnly)
+	if err != nil {
+		return tokenSource{}, err
+	}
+
+	return tokenSource{
+		folders: vault.Folders,
+		code: func(token tlockvault.Token) (string, int, error) {
+			return tlockotp.CurrentCode(token), tlockotp.RemainingTime(token), nil
+		},
+		close: vault.Close,
+	}, nil
+}
+
+// Folders of the user's vault along with ways to add tokens to it
+type tokenWriter struct {
+	// Folders, the secrets of the tokens are not available when served by the agent
+	folders []tlockvault.Folder
+
+	// Adds the token to the folder and returns it
+	add func(folder string, token tlockvault.Token) (tlockvault.Token, error)
+
+	// Imports the tokens, or only works out what importing would do
+	importTokens func(imported tlockvault.Import, dryRun bool) (tlockvault.ImportSummary, error)
+
+	// Releases the writer, flushing the writes
+	close func() error
+}
+
+// Opens the user's vault for adding tokens, through the agent if it is running
+func (options commonOptions) openWriter() (tokenWriter, error) {
+	// Find user
+	user, err := options.findUser()
+	if err != nil {
+		return tokenWriter{}, err
+	}
+
+	// Served by the agent
+	if !options.password.given() {
+		if folders, err := tlockagent.List(user.S()); err == nil {
+			return tokenWriter{
+				folders: folders,
+				add: func(folder string, token tlockvault.Token) (tlockvault.Token, error) {
+					return tlockagent.Add(user.S(), folder, token)
+				},
+				importTokens: func(imported tlockvault.Import, dryRun bool) (tlockvault.ImportSummary, error) {
+					return tlockagent.Import(user.S(), imported, dryRun)
+				},
+				close: func() error { return nil },
+			}, nil
+		}
+	}
+
+	// Open the vault ourselves
+	vault, err := options.unlockVault(user, tlockvault.Load)
+	if err != nil {
+		return tokenWriter{}, err
+	}
+
+	return tokenWriter{
+		folders: vault.Folders,
+		add: func(folder string, token tlockvault.Token) (tlockvault.Token, error) {
+			if err := vault.AddTokenFromToken(folder, token); err != nil {
+				return tlockvault.Token{}, err
+			}
+
+			// The new token is the last one in the folder
+			tokens := vault.GetTokens(folder)
+
+			return tokens[len(tokens)-1], nil
+		},
+		importTokens: func(imported tlockvault.Import, dryRun bool) (tlockvault.ImportSummary, error) {
+			if dryRun {
+				return vault.PlanImport(imported), nil
+			}
+
+			return vault.ApplyImport(imported)
+		},
+		close: vault.Close,
+	}, nil
+}
+
+// Writes the value as indented JSON
+func writeJSON(stdout io.Writer, value any) error {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+// Returns the display name of the token, as `issuer:account`
+func displayName(token tlockvault.Token) string {
+	return strings.Trim(token.Issuer+":"+token.Account, ":")
+}
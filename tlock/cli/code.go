@@ -0,0 +1,142 @@
+package tlockcli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Error representing that no token matches the query
+var ERR_TOKEN_NOT_FOUND = errors.New("No token matches the query")
+
+// Error representing that multiple tokens match the query
+var ERR_TOKEN_AMBIGUOUS = errors.New("Multiple tokens match the query, be more specific")
+
+// Output of `tlock code`
+type codeOutput struct {
+	tokenOutput
+
+	// Current code
+	Code string `json:"code"`
+
+	// Seconds before the code changes, only for TOTP tokens
+	Remaining *int `json:"remaining,omitempty"`
+}
+
+// A token along with the folder it is in
+type folderToken struct {
+	folder string
+	token  tlockvault.Token
+}
+
+// Finds the token matching the query
+// The query matches the ID of a token, or a part of its `issuer:account` name
+func findToken(folders []tlockvault.Folder, folder, query string) (folderToken, error) {
+	var matches []folderToken
+
+	query = strings.ToLower(query)
+
+	for _, f := range folders {
+		if folder != "" && f.Name != folder {
+			continue
+		}
+
+		for _, token := range f.Tokens {
+			name := strings.ToLower(displayName(token))
+
+			// Exact matches always win
+			if token.ID == query || name == query {
+				return folderToken{folder: f.Name, token: token}, nil
+			}
+
+			if strings.Contains(name, query) {
+				matches = append(matches, folderToken{folder: f.Name, token: token})
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return folderToken{}, ERR_TOKEN_NOT_FOUND
+	case 1:
+		return matches[0], nil
+	}
+
+	// List the candidates
+	names := make([]string, len(matches))
+
+	for index, match := range matches {
+		names[index] = fmt.Sprintf("%s (%s)", displayName(match.token), match.folder)
+	}
+
+	return folderToken{}, fmt.Errorf("%w: %s", ERR_TOKEN_AMBIGUOUS, strings.Join(names, ", "))
+}
+
+// `tlock code`
+func codeCommand(args []string, stdout io.Writer) error {
+	var options commonOptions
+	var folder string
+
+	// Flags
+	flags := newFlagSet(
+		"code [flags] <query>",
+		"Prints the current code of the token matching the query, and the seconds before it changes.\nThe query is the ID of the token or a part of its issuer:account name.",
+	)
+	options.register(flags)
+	flags.StringVar(&folder, "folder", "", "only look for the token in this folder")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// Query is required
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	// Open tokens
+	source, err := options.openTokens()
+	if err != nil {
+		return err
+	}
+
+	defer source.close()
+
+	// Find
+	match, err := findToken(source.folders, folder, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	// Generate
+	code, remaining, err := source.code(match.token)
+	if err != nil {
+		return err
+	}
+
+	output := codeOutput{
+		tokenOutput: toTokenOutput(match.folder, match.token),
+		Code:        code,
+	}
+
+	if match.token.Type.TimeBased() {
+		output.Remaining = &remaining
+	}
+
+	// Print
+	if options.json {
+		return writeJSON(stdout, output)
+	}
+
+	if output.Remaining != nil {
+		_, err = fmt.Fprintf(stdout, "%s\t%d\n", output.Code, *output.Remaining)
+	} else {
+		_, err = fmt.Fprintln(stdout, output.Code)
+	}
+
+	return err
+}
@@ -0,0 +1,163 @@
+package tlockcli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/eklairs/tlock/tlock-internal/utils"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	"golang.org/x/term"
+)
+
+// Error representing that a plaintext export was not confirmed
+var ERR_PLAINTEXT_NOT_CONFIRMED = errors.New("The export is not encrypted and anyone who reads it can generate your codes, pass --yes to confirm")
+
+// Error representing that the archive needs a password but there is no way to ask for it
+var ERR_NO_ARCHIVE_PASSWORD = errors.New("Encrypted archives need a password, pass it with --export-password-fd or --export-password-env when not running in a terminal")
+
+// Error representing that the passwords typed for the archive don't match
+var ERR_ARCHIVE_PASSWORD_MISMATCH = errors.New("The passwords do not match")
+
+// Flag that can be given multiple times
+type stringsFlag []string
+
+// String()
+func (values *stringsFlag) String() string {
+	return strings.Join(*values, ", ")
+}
+
+// Set()
+func (values *stringsFlag) Set(value string) error {
+	*values = append(*values, value)
+	return nil
+}
+
+// `tlock export`
+func exportCommand(args []string, stdout io.Writer) (err error) {
+	var options commonOptions
+	var exportPassword passwordOptions
+	var folders stringsFlag
+	var yes bool
+
+	// Flags
+	flags := newFlagSet(
+		"export [flags] <format> <file>",
+		"Exports the tokens, with their folders and HOTP counters, to the file or to stdout if it is -.\nThe formats are uris (otpauth:// URI list), aegis (plain Aegis JSON) and archive (password encrypted tlock archive).",
+	)
+	options.register(flags)
+	exportPassword.registerAs(flags, "export-password", "the password of the archive")
+	flags.Var(&folders, "folder", "only export this folder, can be given multiple times")
+	flags.BoolVar(&yes, "yes", false, "confirm writing an export that is not encrypted")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// Format and file are required
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errUsage
+	}
+
+	format := tlockvault.ExportFormat(flags.Arg(0))
+	path := flags.Arg(1)
+
+	if !slices.Contains(tlockvault.ExportFormats, format) {
+		return tlockvault.ERR_EXPORT_FORMAT
+	}
+
+	// Anyone can read plaintext exports, make sure that is intended
+	if format.Plaintext() && !yes {
+		if err := confirmPlaintext(path); err != nil {
+			return err
+		}
+	}
+
+	// Password of the archive
+	var password string
+
+	if !format.Plaintext() {
+		if password, err = readArchivePassword(exportPassword); err != nil {
+			return err
+		}
+	}
+
+	// Open vault
+	vault, err := options.openVault()
+	if err != nil {
+		return err
+	}
+
+	defer vault.Close()
+
+	// Export
+	data, err := vault.Export(format, folders, password)
+	if err != nil {
+		return err
+	}
+
+	if path == "-" {
+		_, err = stdout.Write(data)
+		return err
+	}
+
+	if err = utils.WriteFileAtomic(path, data, 0o600); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported the tokens to %s\n", path)
+
+	return nil
+}
+
+// Asks to confirm writing a plaintext export
+func confirmPlaintext(path string) error {
+	fd := int(os.Stdin.Fd())
+
+	// There is no one to ask
+	if !term.IsTerminal(fd) {
+		return ERR_PLAINTEXT_NOT_CONFIRMED
+	}
+
+	if path == "-" {
+		path = "stdout"
+	}
+
+	fmt.Fprintf(os.Stderr, "The export is not encrypted, anyone who reads %s can generate your codes. Continue? [y/N] ", path)
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return ERR_PLAINTEXT_NOT_CONFIRMED
+	}
+
+	return nil
+}
+
+// Reads the password of the archive from the given source, or prompts for it twice
+func readArchivePassword(source passwordOptions) (string, error) {
+	password, given, err := source.read()
+	if err != nil || given {
+		return password, err
+	}
+
+	if password, err = promptPassword("Password for the archive", ERR_NO_ARCHIVE_PASSWORD); err != nil {
+		return "", err
+	}
+
+	again, err := promptPassword("Password for the archive, again", ERR_NO_ARCHIVE_PASSWORD)
+	if err != nil {
+		return "", err
+	}
+
+	if password != again {
+		return "", ERR_ARCHIVE_PASSWORD_MISMATCH
+	}
+
+	return password, nil
+}
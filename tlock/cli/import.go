@@ -0,0 +1,251 @@
+package tlockcli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// Error representing that the format to import is not supported
+var ERR_IMPORT_FORMAT = errors.New("Cannot import that format, the supported formats are")
+
+// Folder for the tokens of URI lists that are not in any folder
+const importDefaultFolder = "Imported"
+
+// Error representing that the export is encrypted but there is no way to ask for its password
+var ERR_NO_EXPORT_PASSWORD = errors.New("The export is encrypted, pass its password with --export-password-fd or --export-password-env when not running in a terminal")
+
+// Reads the tokens from the contents of an export file
+type importer func(data []byte, password passwordOptions) (tlockvault.Import, error)
+
+// Importers by the name of the format
+var importers = map[string]importer{
+	"aegis": importAegis,
+	"tlock": importArchive,
+	"uris":  importURIs,
+}
+
+// Skipped entry as printed by the CLI
+type skippedOutput struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Output of `tlock import`
+type importOutput struct {
+	// Whether the vault was left untouched
+	DryRun bool `json:"dry_run"`
+
+	// Folders that are created
+	NewFolders []string `json:"new_folders"`
+
+	// Tokens that are added
+	Added []tokenOutput `json:"added"`
+
+	// Tokens that are already in the vault
+	Duplicates []tokenOutput `json:"duplicates"`
+
+	// Entries that cannot be imported
+	Skipped []skippedOutput `json:"skipped"`
+}
+
+// Converts the summary to its output form
+func toImportOutput(summary tlockvault.ImportSummary, dryRun bool) importOutput {
+	output := importOutput{
+		DryRun:     dryRun,
+		NewFolders: append([]string{}, summary.NewFolders...),
+		Added:      []tokenOutput{},
+		Duplicates: []tokenOutput{},
+		Skipped:    []skippedOutput{},
+	}
+
+	for _, entry := range summary.Added {
+		output.Added = append(output.Added, toTokenOutput(entry.Folder, entry.Token))
+	}
+
+	for _, entry := range summary.Duplicates {
+		output.Duplicates = append(output.Duplicates, toTokenOutput(entry.Folder, entry.Token))
+	}
+
+	for _, entry := range summary.Skipped {
+		output.Skipped = append(output.Skipped, skippedOutput{Name: entry.Name, Reason: entry.Reason})
+	}
+
+	return output
+}
+
+// Returns the names of the supported formats
+func importerNames() string {
+	names := make([]string, 0, len(importers))
+
+	for name := range importers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+// `tlock import`
+func importCommand(args []string, stdout io.Writer) (err error) {
+	var options commonOptions
+	var exportPassword passwordOptions
+	var dryRun bool
+
+	// Flags
+	flags := newFlagSet(
+		"import [flags] <format> <file>",
+		"Imports the tokens from an export file, the formats are aegis (Aegis JSON, encrypted or not), tlock (tlock archive)\nand uris (otpauth:// URI list). Tokens that are already in the vault are left out.",
+	)
+	options.register(flags)
+	exportPassword.registerAs(flags, "export-password", "the password of the export file or archive")
+	flags.BoolVar(&dryRun, "dry-run", false, "only print what would be imported, without changing the vault")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// Format and file are required
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errUsage
+	}
+
+	read, ok := importers[flags.Arg(0)]
+	if !ok {
+		return fmt.Errorf("%w %s", ERR_IMPORT_FORMAT, importerNames())
+	}
+
+	// Read the export
+	data, err := os.ReadFile(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	imported, err := read(data, exportPassword)
+	if err != nil {
+		return err
+	}
+
+	// Open vault
+	writer, err := options.openWriter()
+	if err != nil {
+		return err
+	}
+
+	// Closing flushes the write, so its error matters
+	defer func() {
+		if closeErr := writer.close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	// Import
+	summary, err := writer.importTokens(imported, dryRun)
+	if err != nil {
+		return err
+	}
+
+	// Print
+	output := toImportOutput(summary, dryRun)
+
+	if options.json {
+		return writeJSON(stdout, output)
+	}
+
+	return printImportOutput(stdout, output)
+}
+
+// Prints the summary of the import
+func printImportOutput(stdout io.Writer, output importOutput) error {
+	verb := "Imported"
+
+	if output.DryRun {
+		verb = "Would import"
+	}
+
+	fmt.Fprintf(stdout, "%s %d tokens", verb, len(output.Added))
+
+	if len(output.NewFolders) != 0 {
+		fmt.Fprintf(stdout, ", creating the folders %s", strings.Join(output.NewFolders, ", "))
+	}
+
+	fmt.Fprintln(stdout)
+
+	for _, token := range output.Added {
+		fmt.Fprintf(stdout, "  + %s (%s)\n", strings.Trim(token.Issuer+":"+token.Account, ":"), token.Folder)
+	}
+
+	// Already in the vault
+	if len(output.Duplicates) != 0 {
+		fmt.Fprintf(stdout, "Left out %d duplicate tokens\n", len(output.Duplicates))
+
+		for _, token := range output.Duplicates {
+			fmt.Fprintf(stdout, "  = %s (%s)\n", strings.Trim(token.Issuer+":"+token.Account, ":"), token.Folder)
+		}
+	}
+
+	// Cannot be imported
+	if len(output.Skipped) != 0 {
+		fmt.Fprintf(stdout, "Skipped %d entries\n", len(output.Skipped))
+
+		for _, entry := range output.Skipped {
+			fmt.Fprintf(stdout, "  - %s: %s\n", entry.Name, entry.Reason)
+		}
+	}
+
+	return nil
+}
+
+// Reads an Aegis export, asking for its password if it is encrypted
+func importAegis(data []byte, password passwordOptions) (tlockvault.Import, error) {
+	encrypted, err := tlockvault.AegisEncrypted(data)
+	if err != nil {
+		return tlockvault.Import{}, err
+	}
+
+	if !encrypted {
+		return tlockvault.ParseAegis(data, "")
+	}
+
+	// Get the password from the given source, or prompt for it
+	value, given, err := password.read()
+	if err != nil {
+		return tlockvault.Import{}, err
+	}
+
+	if !given {
+		if value, err = promptPassword("Password for the Aegis export", ERR_NO_EXPORT_PASSWORD); err != nil {
+			return tlockvault.Import{}, err
+		}
+	}
+
+	return tlockvault.ParseAegis(data, value)
+}
+
+// Reads an encrypted tlock archive, asking for its password
+func importArchive(data []byte, password passwordOptions) (tlockvault.Import, error) {
+	value, given, err := password.read()
+	if err != nil {
+		return tlockvault.Import{}, err
+	}
+
+	if !given {
+		if value, err = promptPassword("Password for the archive", ERR_NO_ARCHIVE_PASSWORD); err != nil {
+			return tlockvault.Import{}, err
+		}
+	}
+
+	return tlockvault.ParseArchive(data, value)
+}
+
+// Reads a list of otpauth:// URIs
+func importURIs(data []byte, _ passwordOptions) (tlockvault.Import, error) {
+	return tlockvault.ParseURIList(data, importDefaultFolder)
+}
@@ -0,0 +1,86 @@
+package tlockcli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// A token as printed by the CLI
+type tokenOutput struct {
+	ID      string `json:"id"`
+	Issuer  string `json:"issuer"`
+	Account string `json:"account"`
+	Folder  string `json:"folder"`
+	Type    string `json:"type"`
+}
+
+// Converts the token to its output form
+func toTokenOutput(folder string, token tlockvault.Token) tokenOutput {
+	return tokenOutput{
+		ID:      token.ID,
+		Issuer:  token.Issuer,
+		Account: token.Account,
+		Folder:  folder,
+		Type:    token.Type.String(),
+	}
+}
+
+// `tlock list`
+func listCommand(args []string, stdout io.Writer) error {
+	var options commonOptions
+	var folder string
+
+	// Flags
+	flags := newFlagSet("list [flags]", "Lists the tokens in the vault.")
+	options.register(flags)
+	flags.StringVar(&folder, "folder", "", "only list the tokens in this folder")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// Open tokens
+	source, err := options.openTokens()
+	if err != nil {
+		return err
+	}
+
+	defer source.close()
+
+	// Collect tokens
+	tokens := []tokenOutput{}
+	found := folder == ""
+
+	for _, f := range source.folders {
+		if folder != "" && f.Name != folder {
+			continue
+		}
+
+		found = true
+
+		for _, token := range f.Tokens {
+			tokens = append(tokens, toTokenOutput(f.Name, token))
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %s", tlockvault.ERR_FOLDER_NOT_FOUND, folder)
+	}
+
+	// Print
+	if options.json {
+		return writeJSON(stdout, tokens)
+	}
+
+	writer := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ISSUER\tACCOUNT\tFOLDER")
+
+	for _, token := range tokens {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", token.Issuer, token.Account, token.Folder)
+	}
+
+	return writer.Flush()
+}
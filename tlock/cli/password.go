@@ -0,0 +1,100 @@
+package tlockcli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Error representing that the password is required but there is no way to ask for it
+var ERR_NO_PASSWORD = errors.New("The vault needs a password, pass it with --password-fd or --password-env when not running in a terminal")
+
+// Error representing that the password environment variable is not set
+var ERR_PASSWORD_ENV_UNSET = errors.New("The password environment variable is not set")
+
+// Error representing that the password file descriptor is invalid
+var ERR_PASSWORD_FD_INVALID = errors.New("The password file descriptor cannot be read")
+
+// Sources of the password, other than the prompt
+type passwordOptions struct {
+	// File descriptor to read the password from, -1 if not given
+	fd int
+
+	// Name of the environment variable that holds the password
+	env string
+}
+
+// Registers the flags for the password of the vault
+func (options *passwordOptions) register(flags *flag.FlagSet) {
+	options.registerAs(flags, "password", "the password")
+}
+
+// Registers the flags with the given prefix, like `--<prefix>-fd` and `--<prefix>-env`
+func (options *passwordOptions) registerAs(flags *flag.FlagSet, prefix, what string) {
+	flags.IntVar(&options.fd, prefix+"-fd", -1, fmt.Sprintf("read %s from the first line of this file descriptor", what))
+	flags.StringVar(&options.env, prefix+"-env", "", fmt.Sprintf("read %s from this environment variable", what))
+}
+
+// Returns if a password source was given
+func (options passwordOptions) given() bool {
+	return options.env != "" || options.fd >= 0
+}
+
+// Reads the password from the given source
+// It returns false if no source was given, and the password should be prompted for
+func (options passwordOptions) read() (string, bool, error) {
+	// Environment variable
+	if options.env != "" {
+		password, ok := os.LookupEnv(options.env)
+		if !ok {
+			return "", true, fmt.Errorf("%w: %s", ERR_PASSWORD_ENV_UNSET, options.env)
+		}
+
+		return password, true, nil
+	}
+
+	// File descriptor
+	if options.fd >= 0 {
+		file := os.NewFile(uintptr(options.fd), "password-fd")
+		if file == nil {
+			return "", true, ERR_PASSWORD_FD_INVALID
+		}
+
+		defer file.Close()
+
+		// Read first line
+		line, err := bufio.NewReader(file).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", true, fmt.Errorf("%w: %s", ERR_PASSWORD_FD_INVALID, err)
+		}
+
+		return strings.TrimRight(line, "\r\n"), true, nil
+	}
+
+	return "", false, nil
+}
+
+// Prompts for the password on the terminal
+// The given error is returned when not running in a terminal
+func promptPassword(prompt string, unavailable error) (string, error) {
+	fd := int(os.Stdin.Fd())
+
+	// There is no one to ask
+	if !term.IsTerminal(fd) {
+		return "", unavailable
+	}
+
+	// Prompt on stderr, so that stdout only has the output
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+
+	return string(password), err
+}
@@ -1,17 +1,65 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/muesli/termenv"
+	"golang.org/x/term"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/eklairs/tlock/tlock-internal/clipboard"
 	"github.com/eklairs/tlock/tlock-internal/context"
+	"github.com/eklairs/tlock/tlock-internal/crash"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	tlockcli "github.com/eklairs/tlock/tlock/cli"
 	tlockmodels "github.com/eklairs/tlock/tlock/models"
 	tlockstyles "github.com/eklairs/tlock/tlock/styles"
 )
 
+// Flushes the vaults when the process is asked to terminate
+func handleSignals(program *tea.Program) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	go func() {
+		<-signals
+
+		// Write everything that is pending
+		tlockvault.CloseAll()
+
+		// Don't leave the copied codes behind
+		clipboard.ClearPending()
+
+		// Quit
+		program.Quit()
+	}()
+}
+
+// Restores the terminal to the state it was in before tlock started
+func restoreTerminal(state *term.State) {
+	output := termenv.NewOutput(os.Stdout)
+
+	output.DisableMouseCellMotion()
+	output.ExitAltScreen()
+	output.ShowCursor()
+
+	if state != nil {
+		term.Restore(int(os.Stdin.Fd()), state)
+	}
+}
+
 // TLock go brrr
 func main() {
+	// Subcommands run without the interactive interface
+	if tlockcli.IsCommand(os.Args[1:]) {
+		os.Exit(tlockcli.Run(os.Args[1:]))
+	}
+
 	// Initialize context
 	context := context.InitializeContext()
 	background := termenv.RGBColor(context.GetCurrentTheme().Background)
@@ -19,11 +67,45 @@ func main() {
 	// Initialize styles
 	tlockstyles.InitializeStyles(context.GetCurrentTheme())
 
+	// Save the terminal state to restore it on panics
+	terminalState, _ := term.GetState(int(os.Stdin.Fd()))
+
+	// On panics, restore the terminal and make sure that the pending vault writes are not lost
+	// The root model wraps the commands, so that the panics in their goroutines end up here as well
+	crash.SetHandler(func(reason any, stack []byte) {
+		restoreTerminal(terminalState)
+		tlockvault.CloseAll()
+		clipboard.ClearPending()
+
+		fmt.Printf("tlock crashed: %v\n\n%s", reason, stack)
+		os.Exit(1)
+	})
+
+	defer crash.Recover()
+
 	// New bubbletea program
-	program := tea.NewProgram(tlockmodels.InitializeRootModel(&context), tea.WithAltScreen(), tea.WithBackgroundColor(background), tea.WithMouseCellMotion())
+	// We handle signals and panics ourselves, so that we can flush the vaults
+	program := tea.NewProgram(
+		tlockmodels.InitializeRootModel(&context),
+		tea.WithAltScreen(), tea.WithBackgroundColor(background), tea.WithMouseCellMotion(),
+		tea.WithoutSignalHandler(), tea.WithoutCatchPanics(),
+	)
+
+	// Handle signals
+	handleSignals(program)
 
 	// Run
-	if _, err := program.Run(); err != nil {
+	_, err := program.Run()
+
+	// Write everything that is pending before exiting, even if the interface failed
+	closeErr := tlockvault.CloseAll()
+
+	// Don't leave the copied codes behind
+	clipboard.ClearPending()
 
+	// Report what went wrong
+	if err = errors.Join(err, closeErr); err != nil {
+		fmt.Fprintf(os.Stderr, "tlock: %s\n", err)
+		os.Exit(1)
 	}
 }
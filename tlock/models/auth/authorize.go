@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -144,11 +145,19 @@ func (screen EnterPassScreen) Update(msg tea.Msg, manager *modelmanager.ModelMan
 
 // View
 func (screen EnterPassScreen) View() string {
-	return lipgloss.JoinVertical(
-		lipgloss.Center,
+	items := []string{
 		tlockstyles.Title(screen.ascii), "",
 		tlockstyles.Dimmed(fmt.Sprintf(screen.description, screen.user.S())), "",
 		components.InputGroup("Password", "Enter the super secret password", screen.errorMessage, screen.passInput),
-		tlockstyles.HelpView(enterPassKeys),
-	)
+	}
+
+	// If the vault is damaged, retrying the password won't help; tell where the file is
+	if screen.errorMessage != nil && errors.Is(*screen.errorMessage, tlockvault.ERR_VAULT_CORRUPT) {
+		items = append(items, tlockstyles.Dimmed(fmt.Sprintf("Vault file: %s", screen.user.Vault())), "")
+	}
+
+	// Add help
+	items = append(items, tlockstyles.HelpView(enterPassKeys))
+
+	return lipgloss.JoinVertical(lipgloss.Center, items...)
 }
@@ -64,6 +64,9 @@ type ChangePasswordScreen struct {
 
 	// User
 	user string
+
+	// Any error while changing the password
+	errorMessage *error
 }
 
 // Initializes a new instance of the create user screen
@@ -99,10 +102,11 @@ func (screen ChangePasswordScreen) Update(msg tea.Msg, manager *modelmanager.Mod
 
 		case key.Matches(msgType, changePasswordKeys.Change):
 			// Change password
-			screen.vault.ChangePassword(screen.newPassword.Value())
-
-			// Pop screen
-			manager.PopScreen()
+			if err := screen.vault.ChangePassword(screen.newPassword.Value()); err != nil {
+				screen.errorMessage = &err
+			} else {
+				manager.PopScreen()
+			}
 
 		default:
 			screen.newPassword, _ = screen.newPassword.Update(msg)
@@ -118,7 +122,7 @@ func (screen ChangePasswordScreen) View() string {
 	items := []string{
 		tlockstyles.Title(changePasswordAsciiArt), "",
 		tlockstyles.Dimmed("Change your password"), "",
-		components.InputGroup("New password", "Enter the new password that you want to use to login from next time", nil, screen.newPassword),
+		components.InputGroup("New password", "Enter the new password that you want to use to login from next time", screen.errorMessage, screen.newPassword),
 		tlockstyles.HelpView(changePasswordKeys),
 	}
 
@@ -0,0 +1,425 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/eklairs/tlock/tlock-internal/components"
+	"github.com/eklairs/tlock/tlock-internal/constants"
+	"github.com/eklairs/tlock/tlock-internal/modelmanager"
+	"github.com/eklairs/tlock/tlock-internal/utils"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	tlockstyles "github.com/eklairs/tlock/tlock/styles"
+)
+
+// Export ascii art
+var exportAsciiArt = `
+█▀▀ ▀▄▀ █▀█ █▀█ █▀█ ▀█▀
+██▄ █ █ █▀▀ █▄█ █▀▄  █ `
+
+// Steps of the export
+const (
+	exportStepFormat = iota
+	exportStepFolders
+	exportStepFile
+	exportStepConfirm
+	exportStepDone
+)
+
+// Export formats, along with their description
+var exportFormatOptions = []struct {
+	format tlockvault.ExportFormat
+	title  string
+	desc   string
+}{
+	{tlockvault.ExportFormatArchive, "Encrypted archive", "encrypted"},
+	{tlockvault.ExportFormatAegis, "Aegis JSON", "plaintext"},
+	{tlockvault.ExportFormatURIs, "otpauth:// URI list", "plaintext"},
+}
+
+// Export key map
+type exportKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Toggle key.Binding
+	Enter  key.Binding
+	GoBack key.Binding
+}
+
+// ShortHelp()
+func (k exportKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Toggle, k.GoBack, k.Enter}
+}
+
+// FullHelp()
+func (k exportKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up},
+		{k.Down},
+		{k.Toggle},
+		{k.GoBack},
+		{k.Enter},
+	}
+}
+
+// Keys
+var exportKeys = exportKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "move down"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "toggle folder"),
+	),
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "continue"),
+	),
+	GoBack: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+}
+
+// Key map with a single action
+type confirmExportKeyMap struct {
+	Confirm key.Binding
+	GoBack  key.Binding
+}
+
+// ShortHelp()
+func (k confirmExportKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.GoBack, k.Confirm}
+}
+
+// FullHelp()
+func (k confirmExportKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.GoBack},
+		{k.Confirm},
+	}
+}
+
+// Keys while choosing the file
+var exportFileKeys = confirmExportKeyMap{
+	Confirm: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "continue"),
+	),
+	GoBack: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+}
+
+// Keys to confirm a plaintext export
+var confirmExportKeys = confirmExportKeyMap{
+	Confirm: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "export without encryption"),
+	),
+	GoBack: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+}
+
+// Export screen
+type ExportScreen struct {
+	// Vault
+	vault *tlockvault.Vault
+
+	// Current step
+	step int
+
+	// Focused format or folder
+	focused int
+
+	// Chosen format
+	format tlockvault.ExportFormat
+
+	// Whether each folder is selected
+	selected []bool
+
+	// Path of the export
+	path textinput.Model
+
+	// Password of the archive
+	password textinput.Model
+
+	// Whether the password input is focused
+	passwordFocused bool
+
+	// Number of exported tokens
+	exported int
+
+	// Any error
+	errorMessage *error
+}
+
+// Initializes the export screen
+func InitializeExportScreen(vault *tlockvault.Vault) ExportScreen {
+	// All the folders are exported by default
+	selected := make([]bool, len(vault.Folders))
+
+	for index := range selected {
+		selected[index] = true
+	}
+
+	// Input boxes
+	path := components.InitializeInputBox("Path of the export goes here...")
+
+	password := components.InitializeInputBox("Password for the archive goes here...")
+	password.EchoMode = textinput.EchoPassword
+	password.EchoCharacter = constants.CHAR_ECHO
+
+	return ExportScreen{
+		vault:    vault,
+		selected: selected,
+		path:     path,
+		password: password,
+	}
+}
+
+// Init
+func (screen ExportScreen) Init() tea.Cmd {
+	return nil
+}
+
+// Returns the names of the selected folders
+func (screen ExportScreen) selectedFolders() []string {
+	names := make([]string, 0)
+
+	for index, folder := range screen.vault.Folders {
+		if screen.selected[index] {
+			names = append(names, folder.Name)
+		}
+	}
+
+	return names
+}
+
+// Writes the export
+func (screen *ExportScreen) export() {
+	names := screen.selectedFolders()
+
+	// Export
+	data, err := screen.vault.Export(screen.format, names, screen.password.Value())
+
+	if err == nil {
+		err = utils.WriteFileAtomic(utils.ExpandHome(strings.TrimSpace(screen.path.Value())), data, 0o600)
+	}
+
+	if err != nil {
+		screen.errorMessage = &err
+		screen.step = exportStepFile
+
+		// Show the error under the input it is about
+		if !errors.Is(err, tlockvault.ERR_EXPORT_PASSWORD_EMPTY) {
+			screen.passwordFocused = false
+			screen.password.Blur()
+			screen.path.Focus()
+		}
+
+		return
+	}
+
+	// Count tokens
+	folders, _ := screen.vault.SelectFolders(names)
+
+	for _, folder := range folders {
+		screen.exported += len(folder.Tokens)
+	}
+
+	screen.step = exportStepDone
+}
+
+// Update
+func (screen ExportScreen) Update(msg tea.Msg, manager *modelmanager.ModelManager) (modelmanager.Screen, tea.Cmd) {
+	msgType, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return screen, nil
+	}
+
+	// Go back
+	if key.Matches(msgType, exportKeys.GoBack) || screen.step == exportStepDone {
+		manager.PopScreen()
+		return screen, nil
+	}
+
+	switch screen.step {
+	case exportStepFormat:
+		switch {
+		case key.Matches(msgType, exportKeys.Up) && screen.focused != 0:
+			screen.focused -= 1
+
+		case key.Matches(msgType, exportKeys.Down) && screen.focused != len(exportFormatOptions)-1:
+			screen.focused += 1
+
+		case key.Matches(msgType, exportKeys.Enter):
+			screen.format = exportFormatOptions[screen.focused].format
+			screen.step = exportStepFolders
+			screen.focused = 0
+		}
+
+	case exportStepFolders:
+		switch {
+		case key.Matches(msgType, exportKeys.Up) && screen.focused != 0:
+			screen.focused -= 1
+
+		case key.Matches(msgType, exportKeys.Down) && screen.focused < len(screen.selected)-1:
+			screen.focused += 1
+
+		case key.Matches(msgType, exportKeys.Toggle) && len(screen.selected) != 0:
+			screen.selected[screen.focused] = !screen.selected[screen.focused]
+
+		case key.Matches(msgType, exportKeys.Enter):
+			if len(screen.selectedFolders()) == 0 {
+				err := errors.New("Select at least one folder to export")
+				screen.errorMessage = &err
+				break
+			}
+
+			screen.errorMessage = nil
+			screen.step = exportStepFile
+			screen.path.SetValue("~/tlock-export" + screen.format.Extension())
+			screen.path.CursorEnd()
+			screen.path.Focus()
+		}
+
+	case exportStepFile:
+		switch {
+		case strings.Contains(msgType.String(), "tab"):
+			// We ignore tabs (because of bubbletea issue in windows)
+
+		case key.Matches(msgType, exportKeys.Enter):
+			screen.errorMessage = nil
+
+			// Archives need a password
+			if !screen.format.Plaintext() && !screen.passwordFocused {
+				screen.passwordFocused = true
+				screen.path.Blur()
+				screen.password.Focus()
+				break
+			}
+
+			// Plaintext exports must be confirmed
+			if screen.format.Plaintext() {
+				screen.step = exportStepConfirm
+				break
+			}
+
+			screen.export()
+
+		default:
+			if screen.passwordFocused {
+				screen.password, _ = screen.password.Update(msg)
+			} else {
+				screen.path, _ = screen.path.Update(msg)
+			}
+		}
+
+	case exportStepConfirm:
+		if key.Matches(msgType, confirmExportKeys.Confirm) {
+			screen.export()
+		}
+	}
+
+	return screen, nil
+}
+
+// View
+func (screen ExportScreen) View() string {
+	items := []string{
+		tlockstyles.Title(exportAsciiArt), "",
+	}
+
+	switch screen.step {
+	case exportStepFormat:
+		items = append(items, tlockstyles.Dimmed("Choose the format of the export"), "")
+
+		for index, option := range exportFormatOptions {
+			renderer := components.ListItemInactive
+
+			if index == screen.focused {
+				renderer = components.ListItemActive
+			}
+
+			items = append(items, renderer(65, option.title, option.desc))
+		}
+
+		items = append(items, "", tlockstyles.HelpView(userOptionsKeys))
+
+	case exportStepFolders:
+		items = append(items, tlockstyles.Dimmed("Choose the folders to export"), "")
+
+		for index, folder := range screen.vault.Folders {
+			renderer := components.ListItemInactive
+
+			if index == screen.focused {
+				renderer = components.ListItemActive
+			}
+
+			check := "[ ]"
+
+			if screen.selected[index] {
+				check = "[x]"
+			}
+
+			items = append(items, renderer(65, fmt.Sprintf("%s %s", check, folder.Name), fmt.Sprintf("%d tokens", len(folder.Tokens))))
+		}
+
+		if screen.errorMessage != nil {
+			items = append(items, "", tlockstyles.Styles.Error.Render((*screen.errorMessage).Error()))
+		}
+
+		items = append(items, "", tlockstyles.HelpView(exportKeys))
+
+	case exportStepFile:
+		items = append(items, tlockstyles.Dimmed("Choose where to write the export"), "")
+
+		if screen.passwordFocused {
+			items = append(items, components.InputGroup("Password", "The archive is encrypted with this password, it is needed to import it again", screen.errorMessage, screen.password))
+		} else {
+			items = append(items, components.InputGroup("File", "Path of the file to write the export to", screen.errorMessage, screen.path))
+		}
+
+		items = append(items, tlockstyles.HelpView(exportFileKeys))
+
+	case exportStepConfirm:
+		items = append(
+			items,
+			tlockstyles.Dimmed("The export is not encrypted"), "",
+			lipgloss.JoinHorizontal(
+				lipgloss.Center,
+				tlockstyles.Dimmed("Anyone who can read "),
+				tlockstyles.Title(strings.TrimSpace(screen.path.Value())),
+				tlockstyles.Dimmed(" can "),
+				tlockstyles.Styles.Error.Render("generate your codes"),
+				tlockstyles.Dimmed(", continue?"),
+			), "",
+			tlockstyles.HelpView(confirmExportKeys),
+		)
+
+	case exportStepDone:
+		items = append(
+			items,
+			tlockstyles.Dimmed(fmt.Sprintf("Exported %d tokens to %s", screen.exported, strings.TrimSpace(screen.path.Value()))), "",
+			tlockstyles.Dimmed("Press any key to go back"),
+		)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center, items...)
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"github.com/eklairs/tlock/tlock-internal/context"
+
+	tlockcore "github.com/eklairs/tlock/tlock-core"
+)
+
+// Lock screen ascii art
+var lockedAscii = `
+█   █▀█ █▀▀ █▄▀ █▀▀ █▀▄
+█▄▄ █▄█ █▄▄ █ █ ██▄ █▄▀`
+
+// Initializes the screen shown after the vault was locked for being idle
+// Any error while saving the pending changes before locking is shown on it
+func InitializeLockScreen(context *context.Context, user tlockcore.User, next NextFunc, err error) EnterPassScreen {
+	screen := InitializeEnterPassScreenCustomOpts(context, user, next, lockedAscii, "The vault of %s was locked after being idle, enter the password to unlock it")
+
+	if err != nil {
+		screen.errorMessage = &err
+	}
+
+	return screen
+}
@@ -99,7 +99,7 @@ func (screen UserOptionsScreen) Update(msg tea.Msg, manager *modelmanager.ModelM
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msgType, userOptionsKeys.Down):
-			if screen.focused != 2 {
+			if screen.focused != 3 {
 				screen.focused += 1
 			}
 
@@ -120,6 +120,9 @@ func (screen UserOptionsScreen) Update(msg tea.Msg, manager *modelmanager.ModelM
 				cmd = append(cmd, manager.PushScreen(InitializeChangePasswordScreen(screen.context, screen.vault, screen.user)))
 
 			case 2:
+				cmd = append(cmd, manager.PushScreen(InitializeExportScreen(screen.vault)))
+
+			case 3:
 				cmd = append(cmd, manager.PushScreen(InitializeDeleteUserScreen(screen.user, screen.context)))
 			}
 		}
@@ -152,7 +155,7 @@ func (screen UserOptionsScreen) View() string {
 	}
 
 	// Options
-	options := []string{"Edit username", "Change password", "Delete"}
+	options := []string{"Edit username", "Change password", "Export", "Delete"}
 
 	// Render!
 	for index, option := range options {
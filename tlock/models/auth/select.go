@@ -15,6 +15,7 @@ import (
 	"github.com/eklairs/tlock/tlock-internal/utils"
 	"github.com/eklairs/tlock/tlock/models/dashboard"
 
+	tlockagent "github.com/eklairs/tlock/tlock-agent"
 	tlockcore "github.com/eklairs/tlock/tlock-core"
 	tlockvault "github.com/eklairs/tlock/tlock-vault"
 	tlockstyles "github.com/eklairs/tlock/tlock/styles"
@@ -75,16 +76,17 @@ func (d selectUserDelegate) Render(w io.Writer, m list.Model, index int, listIte
 
 // Select user key map
 type selectUserKeyMap struct {
-	Up      key.Binding
-	Down    key.Binding
-	Enter   key.Binding
-	New     key.Binding
-	Options key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Enter    key.Binding
+	Password key.Binding
+	New      key.Binding
+	Options  key.Binding
 }
 
 // ShortHelp()
 func (k selectUserKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Up, k.Down, k.New, k.Options, k.Enter}
+	return []key.Binding{k.Up, k.Down, k.New, k.Options, k.Password, k.Enter}
 }
 
 // LongHelp()
@@ -93,6 +95,7 @@ func (k selectUserKeyMap) FullHelp() [][]key.Binding {
 		{k.Up},
 		{k.Down},
 		{k.New},
+		{k.Password},
 		{k.Enter},
 	}
 }
@@ -119,6 +122,10 @@ var selectUserKeys = selectUserKeyMap{
 		key.WithKeys("o"),
 		key.WithHelp("o", "user options"),
 	),
+	Password: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "login with password"),
+	),
 }
 
 // Select user
@@ -164,8 +171,8 @@ func (screen SelectUserScreen) Update(msg tea.Msg, manager *modelmanager.ModelMa
 
 			// User options
 		case key.Matches(msgType, selectUserKeys.Options):
-			// Try to unlock vault
-			focused, vault := screen.tryUnlock()
+			// Try to unlock vault, the options need the vault itself
+			focused, vault := screen.tryUnlock(false)
 
 			// If the vault is protected, ask for password
 			if vault == nil {
@@ -179,8 +186,8 @@ func (screen SelectUserScreen) Update(msg tea.Msg, manager *modelmanager.ModelMa
 			}
 
 		case key.Matches(msgType, selectUserKeys.Enter):
-			// Try to unlock vault
-			focused, vault := screen.tryUnlock()
+			// Try to unlock vault, through the agent if it is running
+			focused, vault := screen.tryUnlock(true)
 
 			if vault == nil {
 				// It is encrypted with a password, require password
@@ -189,6 +196,12 @@ func (screen SelectUserScreen) Update(msg tea.Msg, manager *modelmanager.ModelMa
 				// YAY!
 				cmds = append(cmds, manager.PushScreen(dashboard.InitializeDashboardScreen(focused.S(), vault, screen.context)))
 			}
+
+		// Skip the agent, to be able to change the vault
+		case key.Matches(msgType, selectUserKeys.Password):
+			focused := tlockcore.User(screen.listview.SelectedItem().(selectUserListItem))
+
+			cmds = append(cmds, manager.PushScreen(InitializeEnterPassScreen(screen.context, focused, dashboard.InitializeDashboardScreen)))
 		}
 
 	case modelmanager.ScreenRefocusedMsg:
@@ -231,13 +244,19 @@ func (screen SelectUserScreen) View() string {
 	)
 }
 
-// Tries to unlock the vault for the focused user
-func (screen SelectUserScreen) tryUnlock() (tlockcore.User, *tlockvault.Vault) {
+// Tries to unlock the vault for the focused user, falling back to the agent if asked to
+// The vault served by the agent only has the codes, so it cannot be changed
+func (screen SelectUserScreen) tryUnlock(withAgent bool) (tlockcore.User, *tlockvault.Vault) {
 	// Get focused
 	focused := tlockcore.User(screen.listview.SelectedItem().(selectUserListItem))
 
 	// Try to decrypt user with empty password
-	vault, _ := tlockvault.Load(focused.Vault(), "")
+	vault, err := tlockvault.Load(focused.Vault(), "")
+
+	// Served by the agent
+	if err != nil && withAgent {
+		vault, _ = tlockvault.LoadRemote(focused.Vault(), tlockagent.Remote(focused.S()))
+	}
 
 	// Return
 	return focused, vault
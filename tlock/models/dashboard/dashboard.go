@@ -53,6 +53,9 @@ var dashboardKeys dashboardKeyMap
 
 // Dashboard screen
 type DashboardScreen struct {
+	// Name of the user
+	username string
+
 	// Vault
 	vault *tlockvault.Vault
 
@@ -71,6 +74,22 @@ type DashboardScreen struct {
 
 // Initializes a new instance of dashboard screen
 func InitializeDashboardScreen(username string, vault *tlockvault.Vault, context *context.Context) modelmanager.Screen {
+	return initializeDashboardScreen(username, vault, context)
+}
+
+// Initializes a new instance of dashboard screen, focusing the given folder and token
+// Used to restore the selection after the vault was locked
+func InitializeDashboardScreenWithSelection(username string, vault *tlockvault.Vault, context *context.Context, folder, token string) modelmanager.Screen {
+	screen := initializeDashboardScreen(username, vault, context)
+
+	screen.folders.Select(folder)
+	screen.tokens.RestoreSelection(token)
+
+	return screen
+}
+
+// Initializes the dashboard screen
+func initializeDashboardScreen(username string, vault *tlockvault.Vault, context *context.Context) DashboardScreen {
 	// Load keybindings for the user
 	context.Config = config.LoadUserConfig(username)
 
@@ -91,6 +110,7 @@ func InitializeDashboardScreen(username string, vault *tlockvault.Vault, context
 	}
 
 	return DashboardScreen{
+		username:  username,
 		vault:     vault,
 		context:   context,
 		statusbar: components.NewStatusBar(username),
@@ -103,15 +123,31 @@ func InitializeDashboardScreen(username string, vault *tlockvault.Vault, context
 func (screen DashboardScreen) Init() tea.Cmd {
 	var cmd tea.Cmd
 
-	if len(screen.vault.Folders) != 0 {
+	if focused := screen.folders.Focused(); focused != nil {
 		cmd = func() tea.Msg {
 			return tlockmessages.FolderChanged{
-				Folder: screen.vault.Folders[0],
+				Folder: *focused,
 			}
 		}
 	}
 
-	return tea.Batch(cmd, tlockmessages.DispatchRefreshTokensValueMsg())
+	// Let the user know that nothing will be saved
+	var readOnlyCmd tea.Cmd
+
+	if screen.vault.Remote() != nil {
+		readOnlyCmd = func() tea.Msg {
+			return components.StatusBarMsg{Message: "Unlocked by the agent in read-only mode, log in with the password to make changes", ErrorMessage: true}
+		}
+	} else if screen.vault.ReadOnly() {
+		readOnlyCmd = func() tea.Msg {
+			return components.StatusBarMsg{Message: "Opened in read-only mode, the vault is open in another tlock instance", ErrorMessage: true}
+		}
+	}
+
+	// Let the root model know, so that it starts watching for idleness
+	unlockedCmd := func() tea.Msg { return tlockmessages.VaultUnlockedMsg{} }
+
+	return tea.Batch(cmd, readOnlyCmd, unlockedCmd, tlockmessages.DispatchRefreshTokensValueMsg(), tlockmessages.ListenVaultWriteErrors(screen.vault))
 }
 
 // Update
@@ -129,6 +165,43 @@ func (screen DashboardScreen) Update(msg tea.Msg, manager *modelmanager.ModelMan
 		case key.Matches(msgType, dashboardKeys.ChangeTheme):
 			cmd = manager.PushScreen(InitializeThemesScreen(screen.context))
 		}
+
+	// Check if someone else has changed the vault file, piggybacking on the every second refresh
+	case tlockmessages.RefreshTokensValue:
+		if !screen.vault.ChangedOnDisk() {
+			break
+		}
+
+		// Nothing to merge into, the agent already has the changes
+		if screen.vault.Remote() != nil {
+			if err := screen.vault.Reload(); err != nil {
+				cmd = components.StatusBarError(err)
+			} else {
+				cmd = refreshAfterSync("Reloaded the vault from the agent")
+			}
+
+			break
+		}
+
+		cmd = manager.PushScreen(InitializeVaultChangedScreen(screen.vault))
+
+	// Idle for too long, remember the selection and forget the vault
+	// The root model replaces the screens with the lock screen
+	case tlockmessages.LockVaultMsg:
+		locked := tlockmessages.VaultLockedMsg{Username: screen.username}
+
+		if focused := screen.folders.Focused(); focused != nil {
+			locked.Folder = focused.Name
+		}
+
+		if focused := screen.tokens.Focused(); focused != nil {
+			locked.Token = focused.Token.ID
+		}
+
+		// The pending writes are flushed, tell if that failed
+		locked.Err = screen.vault.Forget()
+
+		return screen, func() tea.Msg { return locked }
 	}
 
 	return screen, tea.Batch(screen.folders.Update(msg, manager), screen.tokens.Update(msg, manager), cmd, screen.statusbar.Update(msg))
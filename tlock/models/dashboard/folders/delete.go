@@ -81,7 +81,12 @@ func (screen DeleteFolderScreen) Update(msg tea.Msg, manager *modelmanager.Model
 			manager.PopScreen()
 		case key.Matches(msgType, deleteFolderKeys.Delete):
 			// Delete the folder
-			screen.vault.DeleteFolder(screen.folder.Name)
+			if err := screen.vault.DeleteFolder(screen.folder.Name); err != nil {
+				cmds = append(cmds, components.StatusBarError(err))
+				manager.PopScreen()
+
+				break
+			}
 
 			// Request folders refresh
 			cmds = append(
@@ -5,6 +5,7 @@ import (
 	"io"
 	"math"
 	"os"
+	"slices"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -124,6 +125,14 @@ func InitializeFolders(vault *tlockvault.Vault, context *context.Context) Folder
 	}
 }
 
+// Focuses the folder with the given name, if it exists
+func (folders *Folders) Select(name string) {
+	if index := slices.IndexFunc(folders.vault.Folders, func(folder tlockvault.Folder) bool { return folder.Name == name }); index != -1 {
+		folders.listview.Select(index)
+		folders.lastFocused = index
+	}
+}
+
 // Returns the focused folder item
 func (folders Folders) Focused() *tlockvault.Folder {
 	// If there are no items, return nil
@@ -138,6 +147,13 @@ func (folders Folders) Focused() *tlockvault.Folder {
 	return &focusedItem
 }
 
+// Returns the bindings that change the vault
+func (folders Folders) changeBindings() []key.Binding {
+	config := folders.context.Config.Folder
+
+	return []key.Binding{config.Add.Binding, config.Edit.Binding, config.Delete.Binding, config.Import.Binding, config.MoveUp.Binding, config.MoveDown.Binding}
+}
+
 // Handles update messages
 func (folders *Folders) Update(msg tea.Msg, manager *modelmanager.ModelManager) tea.Cmd {
 	var cmd tea.Cmd
@@ -146,6 +162,11 @@ func (folders *Folders) Update(msg tea.Msg, manager *modelmanager.ModelManager)
 
 	switch msgType := msg.(type) {
 	case tea.KeyMsg:
+		// The agent only serves the codes
+		if folders.vault.Remote() != nil && key.Matches(msgType, folders.changeBindings()...) {
+			return components.StatusBarError(tlockvault.ERR_VAULT_REMOTE)
+		}
+
 		switch {
 		// Add new folder
 		case key.Matches(msgType, folders.context.Config.Folder.Add.Binding):
@@ -163,6 +184,10 @@ func (folders *Folders) Update(msg tea.Msg, manager *modelmanager.ModelManager)
 				cmds = append(cmds, manager.PushScreen(InitializeDeleteFolderScreen(*focused, folders.vault)))
 			}
 
+		// Import tokens
+		case key.Matches(msgType, folders.context.Config.Folder.Import.Binding):
+			cmds = append(cmds, manager.PushScreen(InitializeImportScreen(folders.vault)))
+
 		case key.Matches(msgType, folders.context.Config.Folder.Next.Binding):
 			cmds = append(cmds, func() tea.Msg { return tlockmessages.RequestFolderChanged{} })
 
@@ -172,7 +197,9 @@ func (folders *Folders) Update(msg tea.Msg, manager *modelmanager.ModelManager)
 		// Move folder down
 		case key.Matches(msgType, folders.context.Config.Folder.MoveUp.Binding):
 			if focused := folders.Focused(); focused != nil {
-				if folders.vault.MoveFolderUp(focused.Name) {
+				if moved, err := folders.vault.MoveFolderUp(focused.Name); err != nil {
+					cmds = append(cmds, components.StatusBarError(err))
+				} else if moved {
 					// Move cursor down
 					folders.listview.CursorUp()
 
@@ -190,7 +217,9 @@ func (folders *Folders) Update(msg tea.Msg, manager *modelmanager.ModelManager)
 		// Move folder down
 		case key.Matches(msgType, folders.context.Config.Folder.MoveDown.Binding):
 			if focused := folders.Focused(); focused != nil {
-				if folders.vault.MoveFolderDown(focused.Name) {
+				if moved, err := folders.vault.MoveFolderDown(focused.Name); err != nil {
+					cmds = append(cmds, components.StatusBarError(err))
+				} else if moved {
 					// Move cursor down
 					folders.listview.CursorDown()
 
@@ -0,0 +1,260 @@
+package folders
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/eklairs/tlock/tlock-internal/components"
+	"github.com/eklairs/tlock/tlock-internal/constants"
+	tlockmessages "github.com/eklairs/tlock/tlock-internal/messages"
+	"github.com/eklairs/tlock/tlock-internal/modelmanager"
+	"github.com/eklairs/tlock/tlock-internal/utils"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	tlockstyles "github.com/eklairs/tlock/tlock/styles"
+)
+
+var importAscii = `
+█ █▀▄▀█ █▀█ █▀█ █▀█ ▀█▀
+█ █ ▀ █ █▀▀ █▄█ █▀▄  █ `
+
+// Import key map
+type importKeyMap struct {
+	GoBack key.Binding
+	Enter  key.Binding
+}
+
+// ShortHelp()
+func (k importKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.GoBack, k.Enter}
+}
+
+// FullHelp()
+func (k importKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.GoBack},
+		{k.Enter},
+	}
+}
+
+// Keys while choosing the file
+var importFileKeys = importKeyMap{
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "continue"),
+	),
+	GoBack: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+}
+
+// Keys while reviewing the summary
+var importConfirmKeys = importKeyMap{
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "import"),
+	),
+	GoBack: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+}
+
+// Import screen, for Aegis exports
+type ImportScreen struct {
+	// Path of the export
+	path textinput.Model
+
+	// Password of the export, only asked for encrypted ones
+	password textinput.Model
+
+	// Whether the export is encrypted
+	encrypted bool
+
+	// Tokens read from the export
+	imported tlockvault.Import
+
+	// What importing will do, set once the export has been read
+	summary *tlockvault.ImportSummary
+
+	// Error
+	errorMessage *error
+
+	// Vault
+	vault *tlockvault.Vault
+}
+
+// Initialize import screen
+func InitializeImportScreen(vault *tlockvault.Vault) ImportScreen {
+	// Initialize input boxes
+	path := components.InitializeInputBox("Path to aegis-export.json goes here...")
+	path.Focus()
+
+	password := components.InitializeInputBox("Password of the export goes here...")
+	password.EchoMode = textinput.EchoPassword
+	password.EchoCharacter = constants.CHAR_ECHO
+
+	// Return
+	return ImportScreen{
+		path:     path,
+		password: password,
+		vault:    vault,
+	}
+}
+
+// Reads the export, asking for the password if it is encrypted
+func (screen *ImportScreen) read() {
+	screen.errorMessage = nil
+
+	data, err := os.ReadFile(utils.ExpandHome(strings.TrimSpace(screen.path.Value())))
+	if err != nil {
+		err = errors.New("Cannot read the file, are you sure the path is correct?")
+		screen.errorMessage = &err
+		return
+	}
+
+	// Ask for the password first
+	if !screen.encrypted {
+		if screen.encrypted, err = tlockvault.AegisEncrypted(data); err != nil {
+			screen.errorMessage = &err
+			return
+		}
+
+		if screen.encrypted {
+			screen.path.Blur()
+			screen.password.Focus()
+			return
+		}
+	}
+
+	// Parse
+	if screen.imported, err = tlockvault.ParseAegis(data, screen.password.Value()); err != nil {
+		screen.errorMessage = &err
+		return
+	}
+
+	summary := screen.vault.PlanImport(screen.imported)
+	screen.summary = &summary
+}
+
+// Init
+func (screen ImportScreen) Init() tea.Cmd {
+	return nil
+}
+
+// Update
+func (screen ImportScreen) Update(msg tea.Msg, manager *modelmanager.ModelManager) (modelmanager.Screen, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+
+	switch msgType := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case strings.Contains(msgType.String(), "tab"):
+			// We ignore tabs (because of bubbletea issue in windows)
+		case key.Matches(msgType, importFileKeys.GoBack):
+			manager.PopScreen()
+
+		case key.Matches(msgType, importFileKeys.Enter):
+			// Read the export first
+			if screen.summary == nil {
+				screen.read()
+				break
+			}
+
+			// Import
+			summary, err := screen.vault.ApplyImport(screen.imported)
+			if err != nil {
+				cmds = append(cmds, components.StatusBarError(err))
+				manager.PopScreen()
+
+				break
+			}
+
+			// Request refresh
+			cmds = append(
+				cmds,
+				func() tea.Msg { return tlockmessages.RefreshFoldersMsg{} },
+				func() tea.Msg { return tlockmessages.RefreshTokensMsg{} },
+				func() tea.Msg {
+					return components.StatusBarMsg{Message: fmt.Sprintf("Successfully imported %d tokens", len(summary.Added))}
+				},
+			)
+
+			// Pop
+			manager.PopScreen()
+
+		default:
+			// Send the value to the focused input box
+			if screen.summary == nil && screen.encrypted {
+				screen.password, _ = screen.password.Update(msg)
+			} else if screen.summary == nil {
+				screen.path, _ = screen.path.Update(msg)
+			}
+		}
+	}
+
+	// Return
+	return screen, tea.Batch(cmds...)
+}
+
+// Renders the summary of the import
+func (screen ImportScreen) summaryView() string {
+	summary := screen.summary
+
+	items := []string{
+		tlockstyles.Title(fmt.Sprintf("%d tokens will be imported", len(summary.Added))), "",
+	}
+
+	if len(summary.NewFolders) != 0 {
+		items = append(items, tlockstyles.Dimmed(fmt.Sprintf("New folders: %s", strings.Join(summary.NewFolders, ", "))), "")
+	}
+
+	if len(summary.Duplicates) != 0 {
+		items = append(items, tlockstyles.Dimmed(fmt.Sprintf("%d tokens are already in the vault and will be left out", len(summary.Duplicates))), "")
+	}
+
+	for _, skipped := range summary.Skipped {
+		items = append(items, tlockstyles.Styles.Error.Render(fmt.Sprintf("× %s: %s", skipped.Name, skipped.Reason)))
+	}
+
+	if len(summary.Skipped) != 0 {
+		items = append(items, "")
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center, items...)
+}
+
+// View
+func (screen ImportScreen) View() string {
+	items := []string{
+		tlockstyles.Title(importAscii), "",
+		tlockstyles.Dimmed("Import tokens from an Aegis export"), "",
+	}
+
+	switch {
+	case screen.summary != nil:
+		items = append(items, screen.summaryView(), tlockstyles.HelpView(importConfirmKeys))
+
+	case screen.encrypted:
+		items = append(
+			items,
+			components.InputGroup("Password", "The export is encrypted, enter the password it was exported with", screen.errorMessage, screen.password),
+			tlockstyles.HelpView(importFileKeys),
+		)
+
+	default:
+		items = append(
+			items,
+			components.InputGroup("File", "Path to the JSON file exported from Aegis, encrypted or not", screen.errorMessage, screen.path),
+			tlockstyles.HelpView(importFileKeys),
+		)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center, items...)
+}
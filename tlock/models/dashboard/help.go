@@ -94,6 +94,10 @@ func BuildHelpMenu(context *context.Context) string {
 				Key:  m(context.Config.Folder.Delete.Keys()),
 				Desc: "Delete the current focused folder",
 			},
+			{
+				Key:  m(context.Config.Folder.Import.Keys()),
+				Desc: "Import tokens from an Aegis export",
+			},
 		},
 		Tokens: []HelpKeyBindingSpec{
 			{
@@ -104,6 +108,10 @@ func BuildHelpMenu(context *context.Context) string {
 				Key:  m(context.Config.Tokens.AddScreen.Keys()),
 				Desc: "Add a new token from the screen",
 			},
+			{
+				Key:  m(context.Config.Tokens.AddFile.Keys()),
+				Desc: "Add new tokens from the QR codes in an image file",
+			},
 			{
 				Key:  m(context.Config.Tokens.Edit.Keys()),
 				Desc: "Edit the current focused token",
@@ -116,6 +124,14 @@ func BuildHelpMenu(context *context.Context) string {
 				Key:  m(context.Config.Tokens.NextHOTP.Keys()),
 				Desc: "Generates the token for the next counter [only of HOTP tokens]",
 			},
+			{
+				Key:  m(context.Config.Tokens.ShowQR.Keys()),
+				Desc: "Show the focused token as a QR code to scan it with another app",
+			},
+			{
+				Key:  m(context.Config.Tokens.ResyncHOTP.Keys()),
+				Desc: "Fix the counter from the codes the server accepted [only of HOTP tokens]",
+			},
 			{
 				Key:  m(context.Config.Tokens.Copy.Keys()),
 				Desc: "Copy the current code for the focused token",
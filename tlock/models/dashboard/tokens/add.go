@@ -113,6 +113,14 @@ func (screen AddTokenScreen) Update(msg tea.Msg, manager *modelmanager.ModelMana
 		// Get token
 		token := TokenFromFormData(msgType.Data)
 
+		// Add
+		if err := screen.vault.AddTokenFromToken(screen.folder.Name, token); err != nil {
+			cmds = append(cmds, components.StatusBarError(err))
+			manager.PopScreen()
+
+			break
+		}
+
 		// Make statusbar message
 		statusBarMessage := fmt.Sprintf("Successfully added token for %s", token.Account)
 
@@ -128,9 +136,6 @@ func (screen AddTokenScreen) Update(msg tea.Msg, manager *modelmanager.ModelMana
 			func() tea.Msg { return components.StatusBarMsg{Message: statusBarMessage} },
 		)
 
-		// Add
-		screen.vault.AddTokenFromToken(screen.folder.Name, token)
-
 		// Break
 		manager.PopScreen()
 	}
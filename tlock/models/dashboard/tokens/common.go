@@ -23,7 +23,7 @@ func onlyInt(textinput textinput.Model) textinput.Model {
 }
 
 // Validator for period
-func periodValidator(_ *tlockvault.Vault, period string) error {
+func periodValidator(_ *tlockvault.Vault, period string, _ map[string]string) error {
 	if num, err := strconv.ParseInt(period, 10, 64); err == nil {
 		if num < 1 {
 			return errors.New("Period cannot be < 1")
@@ -34,7 +34,7 @@ func periodValidator(_ *tlockvault.Vault, period string) error {
 }
 
 // Validator for digits
-func digitValidator(_ *tlockvault.Vault, digits string) error {
+func digitValidator(_ *tlockvault.Vault, digits string, _ map[string]string) error {
 	if num, err := strconv.ParseInt(digits, 10, 64); err == nil {
 		if num < 1 {
 			return errors.New("Digits cannot be < 1")
@@ -48,10 +48,10 @@ func digitValidator(_ *tlockvault.Vault, digits string) error {
 	return nil
 }
 
-// Secret validator
-func secretValidator(vault *tlockvault.Vault, secret string) error {
+// Secret validator, the secret is checked against the type selected in the form
+func secretValidator(vault *tlockvault.Vault, secret string, values map[string]string) error {
 	// Validate
-	_, err := vault.ValidateToken(secret)
+	_, err := vault.ValidateToken(toTokenType(values["type"]), secret)
 
 	// Return
 	return err
@@ -75,11 +75,19 @@ func BuildForm(values map[string]string) tlockform.Form {
 	form.AddInput("account", "Account Name", "Name of the account, like John Doe", v(components.InitializeInputBox("Account name goes here..."), "account"), []tlockform.Validator{})
 	form.AddInput("issuer", "Issuer", "Name of the issuer, like GitHub", v(components.InitializeInputBox("Issuer name goes here..."), "issuer"), []tlockform.Validator{})
 	form.AddInput("secret", "Secret", "The secret provided by the issuer", v(components.InitializeInputBox("The secret goes here..."), "secret"), []tlockform.Validator{secretValidator})
-	form.AddOption("type", "Type", "Type of the token", []string{"TOTP", "HOTP"})
+	form.AddOption("type", "Type", "Type of the token", []string{"TOTP", "HOTP", "Steam", "Yandex", "mOTP"})
 	form.AddOption("hash", "Hash", "Hashing algorithm for the token", []string{"SHA1", "SHA256", "SHA512"})
 	form.AddInput("period", "Period", "Time to refresh the token", v(onlyInt(components.InitializeInputBoxCustomWidth("Time in seconds...", 24)), "period"), []tlockform.Validator{periodValidator})
 	form.AddInput("counter", "Initial counter", "Initial counter for HOTP token", v(onlyInt(components.InitializeInputBoxCustomWidth("Initial counter...", 24)), "counter"), []tlockform.Validator{})
 	form.AddInput("digits", "Digits", "Number of digits", v(onlyInt(components.InitializeInputBoxCustomWidth("Number of digits goes here...", 24)), "digits"), []tlockform.Validator{digitValidator})
+	form.AddInput("pin", "PIN", "PIN used with the secret", v(onlyInt(components.InitializeInputBoxCustomWidth("PIN goes here...", 24)), "pin"), []tlockform.Validator{})
+
+	// Select the given options
+	for _, id := range []string{"type", "hash"} {
+		if value, ok := values[id]; ok {
+			form.SelectOption(id, value)
+		}
+	}
 
 	// Set default values
 	form.Default = map[string]string{
@@ -90,8 +98,8 @@ func BuildForm(values map[string]string) tlockform.Form {
 		"digits":  "6",
 	}
 
-	// Disable the counter box
-	form.Disable("counter")
+	// Disable the boxes that the type does not use
+	DisableBasedOnType(&form)
 
 	// Run post init hook
 	form.PostInit()
@@ -131,6 +139,18 @@ func RenderForm(ascii, description string, form tlockform.Form) string {
 		)
 	}
 
+	// Steam Guard tokens have nothing to customize
+	if form.Items[3].FormItem.Value() == "Steam" {
+		items[len(items)-2] = form.Items[3].FormItem.View()
+		inputGroup = tlockstyles.Dimmed("Steam Guard codes are 5 characters long and change every 30 seconds")
+	}
+
+	// Yandex.Key and mOTP tokens only need the PIN
+	if form.Items[3].FormItem.Value() == "Yandex" || form.Items[3].FormItem.Value() == "mOTP" {
+		items[len(items)-2] = form.Items[3].FormItem.View()
+		inputGroup = form.Items[8].FormItem.View()
+	}
+
 	// Add the help menu
 	items = append(items, inputGroup, "", tlockstyles.Help.View(addTokenKeys))
 
@@ -173,18 +193,46 @@ func DisableBasedOnType(form *tlockform.Form) {
 	if form.Items[3].FormItem.Value() == "TOTP" {
 		form.Disable("counter")
 		form.Enable("period")
+		form.Enable("hash")
+		form.Enable("digits")
 	}
 
 	if form.Items[3].FormItem.Value() == "HOTP" {
 		form.Enable("counter")
 		form.Disable("period")
+		form.Enable("hash")
+		form.Enable("digits")
+	}
+
+	// Steam Guard, Yandex.Key and mOTP codes cannot be customized
+	switch form.Items[3].FormItem.Value() {
+	case "Steam", "Yandex", "mOTP":
+		form.Disable("counter")
+		form.Disable("period")
+		form.Disable("hash")
+		form.Disable("digits")
+	}
+
+	// Only Yandex.Key and mOTP codes use a PIN
+	switch form.Items[3].FormItem.Value() {
+	case "Yandex", "mOTP":
+		form.Enable("pin")
+	default:
+		form.Disable("pin")
 	}
 }
 
 // Converts string to token type
 func toTokenType(tokentype string) tlockvault.TokenType {
-	if tokentype == "HOTP" {
+	switch tokentype {
+	case "HOTP":
 		return tlockvault.TokenTypeHOTP
+	case "Steam":
+		return tlockvault.TokenTypeSteam
+	case "Yandex":
+		return tlockvault.TokenTypeYandex
+	case "mOTP":
+		return tlockvault.TokenTypeMOTP
 	}
 
 	return tlockvault.TokenTypeTOTP
@@ -204,7 +252,7 @@ func toOtpAlgorithm(algo string) otp.Algorithm {
 
 // Create a token from form data
 func TokenFromFormData(data map[string]string) tlockvault.Token {
-	return tlockvault.Token{
+	token := tlockvault.Token{
 		Issuer:           data["issuer"],
 		Account:          data["account"],
 		Secret:           data["secret"],
@@ -214,4 +262,13 @@ func TokenFromFormData(data map[string]string) tlockvault.Token {
 		Digits:           utils.ToInt(data["digits"]),
 		HashingAlgorithm: toOtpAlgorithm(data["hash"]),
 	}
+
+	// Steam Guard, Yandex.Key and mOTP tokens cannot be customized
+	token.Normalize()
+
+	if token.Type.HasPIN() {
+		token.PIN = data["pin"]
+	}
+
+	return token
 }
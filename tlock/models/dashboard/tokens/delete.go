@@ -86,7 +86,12 @@ func (screen DeleteTokenScreen) Update(msg tea.Msg, manager *modelmanager.ModelM
 			manager.PopScreen()
 		case key.Matches(msgType, deleteTokenKeys.Delete):
 			// Delete
-			screen.vault.DeleteToken(screen.folder.Name, screen.token)
+			if err := screen.vault.DeleteToken(screen.folder.Name, screen.token.ID); err != nil {
+				cmds = append(cmds, components.StatusBarError(err))
+				manager.PopScreen()
+
+				break
+			}
 
 			accountName := screen.token.Account
 
@@ -89,8 +89,15 @@ type EditTokenScreen struct {
 }
 
 func tokenTypeToString(tokentype tlockvault.TokenType) string {
-	if tokentype == tlockvault.TokenTypeHOTP {
+	switch tokentype {
+	case tlockvault.TokenTypeHOTP:
 		return "HOTP"
+	case tlockvault.TokenTypeSteam:
+		return "Steam"
+	case tlockvault.TokenTypeYandex:
+		return "Yandex"
+	case tlockvault.TokenTypeMOTP:
+		return "mOTP"
 	}
 
 	return "TOTP"
@@ -119,24 +126,9 @@ func InitializeEditTokenScreen(folder tlockvault.Folder, token tlockvault.Token,
 		"period":  fmt.Sprintf("%d", token.Period),
 		"digits":  fmt.Sprintf("%d", token.Digits),
 		"counter": fmt.Sprintf("%d", token.InitialCounter),
+		"pin":     token.PIN,
 	})
 
-	// Override the validator for edit screen for secret
-	form.Items[2].Validators = []func(vault *tlockvault.Vault, value string) error{
-		func(vault *tlockvault.Vault, secret string) error {
-			// Validate
-			_, err := vault.ValidateToken(secret)
-
-			// If it is error because of duplicate secret, and if the secret is same, lets ignore it
-			if secret == token.Secret && err == tlockvault.ERR_TOKEN_EXISTS {
-				return nil
-			}
-
-			// Return
-			return err
-		},
-	}
-
 	// Return
 	return EditTokenScreen{
 		form:     form,
@@ -166,8 +158,18 @@ func (screen EditTokenScreen) Update(msg tea.Msg, manager *modelmanager.ModelMan
 		}
 
 	case form.FormSubmittedMsg:
-		// Get token
+		// Get token, keeping what the form does not show
 		token := TokenFromFormData(msgType.Data)
+		token.Image = screen.token.Image
+		token.Parameters = screen.token.Parameters
+
+		// Replace
+		if err := screen.vault.ReplaceToken(screen.folder.Name, screen.token.ID, token); err != nil {
+			cmds = append(cmds, components.StatusBarError(err))
+			manager.PopScreen()
+
+			break
+		}
 
 		// Make statusbar message
 		statusBarMessage := fmt.Sprintf("Successfully edited token for %s", screen.token.Account)
@@ -184,9 +186,6 @@ func (screen EditTokenScreen) Update(msg tea.Msg, manager *modelmanager.ModelMan
 			func() tea.Msg { return components.StatusBarMsg{Message: statusBarMessage} },
 		)
 
-		// Add
-		screen.vault.ReplaceToken(screen.folder.Name, screen.token, token)
-
 		// Break
 		manager.PopScreen()
 	}
@@ -0,0 +1,267 @@
+package tokens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/eklairs/tlock/tlock-internal/components"
+	tlockmessages "github.com/eklairs/tlock/tlock-internal/messages"
+	"github.com/eklairs/tlock/tlock-internal/modelmanager"
+	"github.com/eklairs/tlock/tlock-internal/utils"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	tlockstyles "github.com/eklairs/tlock/tlock/styles"
+)
+
+var fromFileAsciiArt = `
+█▀▀ █ █   █▀▀
+█▀  █ █▄▄ ██▄`
+
+// From file key map
+type fromFileKeyMap struct {
+	Complete key.Binding
+	GoBack   key.Binding
+	Enter    key.Binding
+}
+
+// ShortHelp()
+func (k fromFileKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Complete, k.GoBack, k.Enter}
+}
+
+// FullHelp()
+func (k fromFileKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Complete},
+		{k.GoBack},
+		{k.Enter},
+	}
+}
+
+// Keys while choosing the image
+var fromFileKeys = fromFileKeyMap{
+	Complete: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "complete"),
+	),
+	GoBack: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "read"),
+	),
+}
+
+// Keys while reviewing the tokens found in the image
+var confirmFromFileKeys = fromFileKeyMap{
+	GoBack: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "add"),
+	),
+}
+
+// Message with the QR codes read from the image
+type imageReadMsg struct {
+	contents []string
+	err      error
+}
+
+// Screen to add the tokens from the QR codes in an image file
+type TokenFromFileScreen struct {
+	// Vault
+	vault *tlockvault.Vault
+
+	// Folder to add the tokens to
+	folder tlockvault.Folder
+
+	// Path of the image
+	path textinput.Model
+
+	// Tokens read from the image
+	imported tlockvault.Import
+
+	// What adding will do, set once the image has been read
+	summary *tlockvault.ImportSummary
+
+	// Whether the image is being read
+	reading bool
+
+	// Error
+	errorMessage *error
+}
+
+// Initializes the screen to add tokens from an image file
+func InitializeTokenFromFileScreen(vault *tlockvault.Vault, folder tlockvault.Folder) TokenFromFileScreen {
+	// Input box, with the paths as completions
+	path := components.InitializeInputBox("Path to the image goes here...")
+	path.ShowSuggestions = true
+	path.CompletionStyle = tlockstyles.Styles.Placeholder
+	path.Focus()
+
+	return TokenFromFileScreen{
+		vault:  vault,
+		folder: folder,
+		path:   path,
+	}
+}
+
+// Init
+func (screen TokenFromFileScreen) Init() tea.Cmd {
+	return nil
+}
+
+// Reads the QR codes from the image in the background, large images take a while to decode
+func readImage(path string) tea.Cmd {
+	return func() tea.Msg {
+		contents, err := utils.ReadTokensFromImageFile(utils.ExpandHome(strings.TrimSpace(path)))
+
+		return imageReadMsg{contents: contents, err: err}
+	}
+}
+
+// Shows what adding the QR codes read from the image will do
+func (screen *TokenFromFileScreen) read(msg imageReadMsg) {
+	screen.reading = false
+
+	if msg.err != nil {
+		screen.errorMessage = &msg.err
+		return
+	}
+
+	screen.errorMessage = nil
+	screen.imported = tlockvault.ParseQRCodes(msg.contents, screen.folder.Name)
+
+	summary := screen.vault.PlanImport(screen.imported)
+	screen.summary = &summary
+}
+
+// Update
+func (screen TokenFromFileScreen) Update(msg tea.Msg, manager *modelmanager.ModelManager) (modelmanager.Screen, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+
+	if msg, ok := msg.(imageReadMsg); ok {
+		screen.read(msg)
+		return screen, nil
+	}
+
+	msgType, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return screen, nil
+	}
+
+	switch {
+	case key.Matches(msgType, fromFileKeys.GoBack):
+		// Choose another image
+		if screen.summary != nil {
+			screen.summary = nil
+			break
+		}
+
+		manager.PopScreen()
+
+	case screen.reading:
+		// Wait for the image
+
+	case key.Matches(msgType, fromFileKeys.Enter):
+		// Read the image first
+		if screen.summary == nil {
+			screen.reading = true
+			cmds = append(cmds, readImage(screen.path.Value()))
+
+			break
+		}
+
+		// Add
+		summary, err := screen.vault.ApplyImport(screen.imported)
+		if err != nil {
+			cmds = append(cmds, components.StatusBarError(err))
+			manager.PopScreen()
+
+			break
+		}
+
+		// Require refresh of folders and tokens list
+		cmds = append(
+			cmds,
+			func() tea.Msg { return tlockmessages.RefreshFoldersMsg{} },
+			func() tea.Msg { return tlockmessages.RefreshTokensMsg{} },
+			func() tea.Msg {
+				return components.StatusBarMsg{Message: fmt.Sprintf("Successfully added %d tokens from the image", len(summary.Added))}
+			},
+		)
+
+		manager.PopScreen()
+
+	case screen.summary == nil:
+		// Tabs complete the path here, instead of being ignored
+		screen.path, _ = screen.path.Update(msg)
+		screen.path.SetSuggestions(utils.CompletePath(screen.path.Value(), utils.ImageExtensions))
+	}
+
+	return screen, tea.Batch(cmds...)
+}
+
+// Renders what adding the tokens will do
+func (screen TokenFromFileScreen) summaryView() string {
+	summary := screen.summary
+
+	items := []string{
+		fmt.Sprintf("%s %s", tlockstyles.Styles.SubText.Render("Found"), tlockstyles.Styles.Title.Render(fmt.Sprintf("%d new tokens", len(summary.Added)))), "",
+	}
+
+	// Tokens
+	for index, entry := range summary.Added {
+		if index == migrationPreviewSize {
+			items = append(items, tlockstyles.Dimmed(fmt.Sprintf("and %d more", len(summary.Added)-migrationPreviewSize)))
+			break
+		}
+
+		items = append(items, tlockstyles.Dimmed(strings.Trim(entry.Token.Issuer+":"+entry.Token.Account, ":")))
+	}
+
+	if len(summary.Duplicates) != 0 {
+		items = append(items, "", tlockstyles.Dimmed(fmt.Sprintf("%d tokens are already in the vault and will be left out", len(summary.Duplicates))))
+	}
+
+	for _, skipped := range summary.Skipped {
+		items = append(items, tlockstyles.Styles.Error.Render(fmt.Sprintf("× %s: %s", skipped.Name, skipped.Reason)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center, items...)
+}
+
+// View
+func (screen TokenFromFileScreen) View() string {
+	items := []string{
+		tlockstyles.Title(fromFileAsciiArt), "",
+	}
+
+	if screen.summary != nil {
+		items = append(
+			items,
+			tlockstyles.Dimmed(fmt.Sprintf("Confirm addition of the tokens to %s", screen.folder.Name)), "",
+			screen.summaryView(), "",
+			tlockstyles.HelpView(confirmFromFileKeys),
+		)
+	} else if screen.reading {
+		items = append(items, tlockstyles.Dimmed("Reading the QR codes in the image..."))
+	} else {
+		items = append(
+			items,
+			tlockstyles.Dimmed("Add tokens from the QR codes in an image"), "",
+			components.InputGroup("Image", "Path to a PNG, JPEG or GIF image, every QR code in it is read", screen.errorMessage, screen.path),
+			tlockstyles.HelpView(fromFileKeys),
+		)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center, items...)
+}
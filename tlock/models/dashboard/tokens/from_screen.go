@@ -1,6 +1,7 @@
 package tokens
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,12 +10,13 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/eklairs/tlock/tlock-internal/components"
+	"github.com/eklairs/tlock/tlock-internal/config"
+	"github.com/eklairs/tlock/tlock-internal/crash"
 	tlockmessages "github.com/eklairs/tlock/tlock-internal/messages"
 	"github.com/eklairs/tlock/tlock-internal/modelmanager"
 	"github.com/eklairs/tlock/tlock-internal/utils"
 	tlockvault "github.com/eklairs/tlock/tlock-vault"
 	tlockstyles "github.com/eklairs/tlock/tlock/styles"
-	"github.com/pquerna/otp"
 )
 
 var MeterV2 = spinner.Spinner{
@@ -31,14 +33,29 @@ var MeterV2 = spinner.Spinner{
 	FPS: time.Second / 8, //nolint:gomnd
 }
 
-// Channel to send the token read from the screen
+// Channel to send the tokens read from the screen
 var dataFromScreenChan = make(chan *dataFromScreen)
 
+// QR code read from the screen that is not a Google Authenticator export
+type screenCode struct {
+	// Content of the QR code
+	Uri string
+
+	// Token, if it can be added
+	Token *tlockvault.Token
+
+	// Why it cannot be added
+	Err error
+}
+
 type dataFromScreen struct {
-	// The otp
-	Uri *string
+	// QR codes with a single token, in the order they were found
+	Codes []screenCode
+
+	// QR codes of Google Authenticator exports
+	Migrations []tlockvault.MigrationPart
 
-	// Any error from validators
+	// Any error while reading the screen
 	Err error
 }
 
@@ -98,6 +115,9 @@ var fromScreenKeys = fromScreenKeyMap{
 
 // Confirm from screen keys
 type confirmScreenKeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	Toggle   key.Binding
 	Continue key.Binding
 	Retake   key.Binding
 	Escape   key.Binding
@@ -105,7 +125,7 @@ type confirmScreenKeyMap struct {
 
 // ShortHelp()
 func (k confirmScreenKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Escape, k.Continue, k.Retake}
+	return []key.Binding{k.Escape, k.Toggle, k.Continue, k.Retake}
 }
 
 // FullHelp()
@@ -115,6 +135,18 @@ func (k confirmScreenKeyMap) FullHelp() [][]key.Binding {
 
 // Keys
 var confirmScreenKeys = confirmScreenKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "move down"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "toggle"),
+	),
 	Continue: key.NewBinding(
 		key.WithKeys("enter"),
 		key.WithHelp("enter", "continue"),
@@ -139,18 +171,27 @@ type TokenFromScreen struct {
 	// Spinner
 	spinner spinner.Model
 
-	// Token read from string
+	// Tokens read from the screen
 	token *dataFromScreen
 
+	// Whether each QR code with a single token is selected to be added
+	selected []bool
+
+	// Focused QR code
+	focused int
+
 	// Folder
 	folder tlockvault.Folder
 
-	// Status bar message to send
-	statusBarMessage string
+	// How to capture the screen
+	capture config.ScreenCaptureConfig
+
+	// Accounts gathered from the QR codes of a Google Authenticator export
+	batch *migrationBatch
 }
 
 // Initializes a new instance of fromScreen from screen
-func InitializeTokenFromScreen(vault *tlockvault.Vault, folder tlockvault.Folder) TokenFromScreen {
+func InitializeTokenFromScreen(vault *tlockvault.Vault, folder tlockvault.Folder, capture config.ScreenCaptureConfig) TokenFromScreen {
 	// Initialize spinner
 	s := spinner.New()
 	s.Spinner = MeterV2
@@ -162,6 +203,8 @@ func InitializeTokenFromScreen(vault *tlockvault.Vault, folder tlockvault.Folder
 		vault:   vault,
 		spinner: s,
 		folder:  folder,
+		capture: capture,
+		batch:   &migrationBatch{},
 	}
 }
 
@@ -170,6 +213,90 @@ func (screen TokenFromScreen) Init() tea.Cmd {
 	return pollDataFetched()
 }
 
+// Reads the QR codes on the screen, and works out which ones can be added
+func readFromScreen(vault *tlockvault.Vault, folder string, capture config.ScreenCaptureConfig) *dataFromScreen {
+	// Read data from the screen
+	uris, err := utils.ReadTokensFromScreen(capture.Command, capture.SelectRegion)
+
+	data := dataFromScreen{Err: err}
+
+	for _, uri := range uris {
+		// Google Authenticator export, with many accounts
+		if tlockvault.IsMigrationURI(uri) {
+			if part, err := tlockvault.ParseGoogleMigration(uri, folder); err != nil {
+				data.Codes = append(data.Codes, screenCode{Uri: uri, Err: err})
+			} else {
+				data.Migrations = append(data.Migrations, part)
+			}
+
+			continue
+		}
+
+		// Try to parse
+		code := screenCode{Uri: uri}
+
+		if token, err := tlockvault.TokenFromURI(uri); err != nil {
+			code.Err = errors.New("Not a token")
+		} else if _, err := vault.ValidateToken(token.Type, token.Secret); err != nil {
+			code.Err = err
+		} else {
+			code.Token = &token
+		}
+
+		data.Codes = append(data.Codes, code)
+	}
+
+	return &data
+}
+
+// Adds the selected tokens, along with the accounts of the Google Authenticator export
+func (screen TokenFromScreen) add() tea.Cmd {
+	imported := 0
+	added := make([]tlockvault.Token, 0)
+
+	// Import the accounts of the export
+	if len(screen.batch.imported.Entries) != 0 {
+		summary, err := screen.vault.ApplyImport(screen.batch.imported)
+		if err != nil {
+			return components.StatusBarError(err)
+		}
+
+		imported = len(summary.Added)
+	}
+
+	// Add tokens
+	// We can ignore validations because we have already pre-checked them
+	for index, code := range screen.token.Codes {
+		if screen.selected[index] {
+			if err := screen.vault.AddTokenFromToken(screen.folder.Name, *code.Token); err != nil {
+				return components.StatusBarError(err)
+			}
+
+			added = append(added, *code.Token)
+		}
+	}
+
+	var message string
+
+	switch {
+	case imported+len(added) == 0:
+		return nil
+	case len(added) == 0:
+		message = fmt.Sprintf("Successfully imported %d tokens from Google Authenticator", imported)
+	case imported == 0 && len(added) == 1:
+		message = addedFromScreenMessage(added[0])
+	default:
+		message = fmt.Sprintf("Successfully added %d tokens from screen", imported+len(added))
+	}
+
+	// Require refresh of folders and tokens list
+	return tea.Batch(
+		func() tea.Msg { return tlockmessages.RefreshFoldersMsg{} },
+		func() tea.Msg { return tlockmessages.RefreshTokensMsg{} },
+		func() tea.Msg { return components.StatusBarMsg{Message: message} },
+	)
+}
+
 // Update
 func (screen TokenFromScreen) Update(msg tea.Msg, manager *modelmanager.ModelManager) (modelmanager.Screen, tea.Cmd) {
 	cmds := make([]tea.Cmd, 0)
@@ -187,63 +314,55 @@ func (screen TokenFromScreen) Update(msg tea.Msg, manager *modelmanager.ModelMan
 			cmds = append(cmds, screen.spinner.Tick)
 
 			go func() {
-				// Read data from the screen
-				data, err := utils.ReadTokenFromScreen()
-
-				// Prepare data
-				dataScreen := dataFromScreen{
-					Uri: data,
-					Err: err,
-				}
-
-				// Try to parse
-				if data != nil {
-					if key, err := otp.NewKeyFromURL(*data); err == nil {
-						// Run validator
-						_, err := screen.vault.ValidateToken(key.Secret())
-
-						// Update error message
-						if dataScreen.Err == nil {
-							dataScreen.Err = err
-						}
-					}
-				}
-
-				// Send
-				dataFromScreenChan <- &dataScreen
+				defer crash.Recover()
+
+				dataFromScreenChan <- readFromScreen(screen.vault, screen.folder.Name, screen.capture)
 			}()
 
+		case screen.state != stateConfirm:
+			// Nothing else to do until the tokens are read
+
 		case key.Matches(msgType, confirmScreenKeys.Retake):
-			if screen.state == stateConfirm {
-				// Set state
-				screen.state = stateTake
+			// Set state
+			screen.state = stateTake
 
-				// Restart poll
-				cmds = append(cmds, pollDataFetched())
-			}
+			// Restart poll
+			cmds = append(cmds, pollDataFetched())
+
+		case key.Matches(msgType, confirmScreenKeys.Up) && screen.focused != 0:
+			screen.focused -= 1
 
-		case key.Matches(msgType, confirmScreenKeys.Continue) && screen.state == stateConfirm:
-			// Add the token
-			if screen.token != nil && screen.token.Err == nil {
-				// Add token
-				// We can ignore validations because we have already pre-checked it
-				screen.vault.AddToken(screen.folder.Name, *screen.token.Uri)
-
-				// Require refresh of folders and tokens list
-				cmds = append(
-					cmds,
-					func() tea.Msg { return tlockmessages.RefreshFoldersMsg{} },
-					func() tea.Msg { return tlockmessages.RefreshTokensMsg{} },
-					func() tea.Msg { return components.StatusBarMsg{Message: screen.statusBarMessage} },
-				)
+		case key.Matches(msgType, confirmScreenKeys.Down) && screen.focused < len(screen.selected)-1:
+			screen.focused += 1
+
+		case key.Matches(msgType, confirmScreenKeys.Toggle) && len(screen.selected) != 0:
+			// Only tokens that can be added can be selected
+			if screen.token.Codes[screen.focused].Err == nil {
+				screen.selected[screen.focused] = !screen.selected[screen.focused]
 			}
 
+		case key.Matches(msgType, confirmScreenKeys.Continue):
+			cmds = append(cmds, screen.add())
+
 			manager.PopScreen()
 		}
 
 	case dataRecievedMsg:
 		screen.token = msgType.data
 		screen.state = stateConfirm
+		screen.focused = 0
+
+		// Every token that can be added is selected by default
+		screen.selected = make([]bool, len(screen.token.Codes))
+
+		for index, code := range screen.token.Codes {
+			screen.selected[index] = code.Err == nil
+		}
+
+		// Gather the accounts of the export
+		for _, part := range screen.token.Migrations {
+			screen.batch.add(part)
+		}
 	}
 
 	if screen.state == stateGathering {
@@ -255,14 +374,58 @@ func (screen TokenFromScreen) Update(msg tea.Msg, manager *modelmanager.ModelMan
 	return screen, tea.Batch(cmds...)
 }
 
+// Renders the list of the QR codes with a single token
+func (screen TokenFromScreen) codesView() string {
+	items := []string{
+		fmt.Sprintf("%s %s", tlockstyles.Styles.SubText.Render("Found"), tlockstyles.Styles.Title.Render(fmt.Sprintf("%d QR codes", len(screen.token.Codes)))), "",
+	}
+
+	for index, code := range screen.token.Codes {
+		renderer := components.ListItemInactive
+
+		if index == screen.focused {
+			renderer = components.ListItemActive
+		}
+
+		// Tokens that cannot be added show why
+		// They are named by their position, the content of the QR code might carry a secret
+		if code.Err != nil {
+			items = append(items, renderer(65, fmt.Sprintf("[-] QR code %d", index+1), code.Err.Error()))
+			continue
+		}
+
+		check := "[ ]"
+
+		if screen.selected[index] {
+			check = "[x]"
+		}
+
+		accountName := code.Token.Account
+
+		if accountName == "" {
+			accountName = "<no account name>"
+		}
+
+		items = append(items, renderer(65, fmt.Sprintf("%s %s", check, accountName), code.Token.Issuer))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center, items...)
+}
+
 // View
 func (screen TokenFromScreen) View() string {
 	switch screen.state {
 	case stateTake:
+		hint := "Place your QRCode window on any of your screens"
+
+		if screen.capture.SelectRegion && len(screen.capture.Command) == 0 && utils.IsWayland() {
+			hint = "Place your QRCode window on any of your screens, and select it once started"
+		}
+
 		return lipgloss.JoinVertical(
 			lipgloss.Center,
 			tlockstyles.Styles.Title.Render(fromScreenAsciiArt), "",
-			tlockstyles.Styles.SubText.Render("Place your QRCode window in the same screen as tlock"), "",
+			tlockstyles.Styles.SubText.Render(hint), "",
 			lipgloss.JoinHorizontal(
 				lipgloss.Center,
 				tlockstyles.Styles.MockScreen.Render("TLock"), "   ",
@@ -277,41 +440,24 @@ func (screen TokenFromScreen) View() string {
 	case stateConfirm:
 		items := []string{
 			tlockstyles.Styles.Title.Render(fromScreenAsciiArt), "",
-			tlockstyles.Styles.SubText.Render("Confirm addition of the token"), "",
+			tlockstyles.Styles.SubText.Render("Confirm addition of the tokens"), "",
 		}
 
-		// If the token is null, show the message
-		if screen.token != nil {
-			// Try to parse the otp value
-			if screen.token.Err == nil {
-				key, err := otp.NewKeyFromURL(*screen.token.Uri)
-
-				// If there was error while finding
-				if err != nil {
-					// Show the error
-					items = append(items, tlockstyles.Styles.Error.Render("Did not find any token!"))
-
-					// Reset token screen before its not what we were looking for
-					screen.token = nil
-				} else {
-					// Find the account name
-					accountName := key.AccountName()
-					screen.statusBarMessage = fmt.Sprintf("Successfully added token for %s from screen", accountName)
-
-					if accountName == "" {
-						accountName = "<no account name>"
-						screen.statusBarMessage = fmt.Sprintf("Successfully added token from screen (no account name)")
-					}
-
-					// Show to user
-					items = append(items, fmt.Sprintf("%s %s", tlockstyles.Styles.SubText.Render("Found a token for"), tlockstyles.Styles.Title.Render(accountName)))
-				}
-			} else {
-				items = append(items, lipgloss.JoinHorizontal(
-					lipgloss.Center,
-					tlockstyles.Styles.Error.Render(screen.token.Err.Error()),
-				))
+		if screen.token.Err != nil {
+			items = append(items, tlockstyles.Styles.Error.Render(screen.token.Err.Error()))
+		}
+
+		// Accounts of the Google Authenticator export, gathered across retakes
+		if len(screen.batch.imported.Entries) != 0 || len(screen.batch.imported.Skipped) != 0 {
+			items = append(items, screen.batch.view(screen.vault))
+		}
+
+		if len(screen.token.Codes) != 0 {
+			if len(screen.batch.imported.Entries) != 0 {
+				items = append(items, "")
 			}
+
+			items = append(items, screen.codesView())
 		}
 
 		// Add help
@@ -325,3 +471,12 @@ func (screen TokenFromScreen) View() string {
 
 	return "Loading..."
 }
+
+// Returns the status bar message for the token added from the screen
+func addedFromScreenMessage(token tlockvault.Token) string {
+	if token.Account != "" {
+		return fmt.Sprintf("Successfully added token for %s from screen", token.Account)
+	}
+
+	return "Successfully added token from screen (no account name)"
+}
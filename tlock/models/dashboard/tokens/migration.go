@@ -0,0 +1,84 @@
+package tokens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	tlockstyles "github.com/eklairs/tlock/tlock/styles"
+)
+
+// Number of accounts listed in the preview
+const migrationPreviewSize = 8
+
+// Accounts gathered from the QR codes of a Google Authenticator export
+type migrationBatch struct {
+	// ID of the batch
+	id int
+
+	// Number of QR codes in the batch
+	size int
+
+	// Indexes of the QR codes scanned so far
+	scanned map[int]bool
+
+	// Accounts in the scanned QR codes
+	imported tlockvault.Import
+}
+
+// Adds the accounts of a QR code to the batch
+// Scanning a QR code of another export starts over
+func (batch *migrationBatch) add(part tlockvault.MigrationPart) {
+	if batch.scanned == nil || batch.id != part.BatchID || batch.size != part.BatchSize {
+		*batch = migrationBatch{id: part.BatchID, size: part.BatchSize, scanned: make(map[int]bool)}
+	}
+
+	// Same QR code scanned again
+	if batch.scanned[part.BatchIndex] {
+		return
+	}
+
+	batch.scanned[part.BatchIndex] = true
+	batch.imported.Entries = append(batch.imported.Entries, part.Entries...)
+	batch.imported.Skipped = append(batch.imported.Skipped, part.Skipped...)
+}
+
+// Checks if all the QR codes of the batch have been scanned
+func (batch migrationBatch) complete() bool {
+	return len(batch.scanned) == batch.size
+}
+
+// Renders the preview of what importing the batch will do
+func (batch migrationBatch) view(vault *tlockvault.Vault) string {
+	summary := vault.PlanImport(batch.imported)
+
+	items := []string{
+		fmt.Sprintf("%s %s", tlockstyles.Styles.SubText.Render("Found a Google Authenticator export with"), tlockstyles.Styles.Title.Render(fmt.Sprintf("%d new tokens", len(summary.Added)))), "",
+	}
+
+	// Accounts
+	for index, entry := range summary.Added {
+		if index == migrationPreviewSize {
+			items = append(items, tlockstyles.Dimmed(fmt.Sprintf("and %d more", len(summary.Added)-migrationPreviewSize)))
+			break
+		}
+
+		items = append(items, tlockstyles.Dimmed(strings.Trim(entry.Token.Issuer+":"+entry.Token.Account, ":")))
+	}
+
+	// Remaining QR codes
+	if !batch.complete() {
+		items = append(items, "", tlockstyles.Styles.Error.Render(fmt.Sprintf("Scanned %d of %d QR codes, show the next one and retake to add its tokens too", len(batch.scanned), batch.size)))
+	}
+
+	if len(summary.Duplicates) != 0 {
+		items = append(items, "", tlockstyles.Dimmed(fmt.Sprintf("%d tokens are already in the vault and will be left out", len(summary.Duplicates))))
+	}
+
+	for _, skipped := range summary.Skipped {
+		items = append(items, tlockstyles.Styles.Error.Render(fmt.Sprintf("× %s: %s", skipped.Name, skipped.Reason)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center, items...)
+}
@@ -0,0 +1,42 @@
+package tokens
+
+import (
+	"testing"
+
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+)
+
+// QR code of a batch with a single account
+func migrationPart(id, size, index int, account string) tlockvault.MigrationPart {
+	return tlockvault.MigrationPart{
+		Import:     tlockvault.Import{Entries: []tlockvault.ImportEntry{{Folder: "Work", Token: tlockvault.Token{Account: account}}}},
+		BatchID:    id,
+		BatchSize:  size,
+		BatchIndex: index,
+	}
+}
+
+func TestMigrationBatch(t *testing.T) {
+	var batch migrationBatch
+
+	steps := []struct {
+		name     string
+		part     tlockvault.MigrationPart
+		accounts int
+		complete bool
+	}{
+		{"first QR code", migrationPart(1, 3, 0, "a"), 1, false},
+		{"same QR code again", migrationPart(1, 3, 0, "a"), 1, false},
+		{"second QR code", migrationPart(1, 3, 2, "c"), 2, false},
+		{"QR code of another export", migrationPart(2, 2, 1, "x"), 1, false},
+		{"rest of the other export", migrationPart(2, 2, 0, "y"), 2, true},
+	}
+
+	for _, step := range steps {
+		batch.add(step.part)
+
+		if accounts := len(batch.imported.Entries); accounts != step.accounts || batch.complete() != step.complete {
+			t.Errorf("%s: got %d accounts and complete %v, want %d and %v", step.name, accounts, batch.complete(), step.accounts, step.complete)
+		}
+	}
+}
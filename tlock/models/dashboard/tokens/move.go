@@ -143,7 +143,12 @@ func (screen MoveTokenScreen) Update(msg tea.Msg, manager *modelmanager.ModelMan
 			focusedFolder := screen.listview.Items()[screen.listview.Index()].(moveTokenListItem)
 
 			// Move token
-			screen.vault.MoveToken(screen.token, screen.folder.Name, focusedFolder.Name)
+			if err := screen.vault.MoveToken(screen.token.ID, screen.folder.Name, focusedFolder.Name); err != nil {
+				cmds = append(cmds, components.StatusBarError(err))
+				manager.PopScreen()
+
+				break
+			}
 
 			accountName := screen.token.Account
 
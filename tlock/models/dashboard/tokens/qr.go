@@ -0,0 +1,295 @@
+package tokens
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/eklairs/tlock/tlock-internal/components"
+	"github.com/eklairs/tlock/tlock-internal/constants"
+	"github.com/eklairs/tlock/tlock-internal/modelmanager"
+	"github.com/eklairs/tlock/tlock-internal/utils"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	tlockstyles "github.com/eklairs/tlock/tlock/styles"
+)
+
+var qrCodeAscii = `
+█▀█ █▀█   █▀▀ █▀█ █▀▄ █▀▀
+▀▀█ █▀▄   █▄▄ █▄█ █▄▀ ██▄`
+
+// Steps of the QR code screen
+const (
+	qrCodeStepPassword = iota
+	qrCodeStepShow
+	qrCodeStepPNG
+)
+
+// QR code key map
+type qrCodeKeyMap struct {
+	SavePNG key.Binding
+	Enter   key.Binding
+	GoBack  key.Binding
+}
+
+// ShortHelp()
+func (k qrCodeKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.GoBack, k.SavePNG, k.Enter}
+}
+
+// FullHelp()
+func (k qrCodeKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.GoBack},
+		{k.SavePNG},
+		{k.Enter},
+	}
+}
+
+// Keys while entering the password
+var qrCodePasswordKeys = qrCodeKeyMap{
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "show"),
+	),
+	GoBack: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+}
+
+// Keys while the QR code is shown
+var qrCodeShowKeys = qrCodeKeyMap{
+	SavePNG: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "save as png"),
+	),
+	GoBack: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+}
+
+// Keys while choosing the path of the PNG
+var qrCodePNGKeys = qrCodeKeyMap{
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "save"),
+	),
+	GoBack: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+}
+
+// Style of the QR code, scanners need dark modules on a light background whatever the theme is
+var qrCodeStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#000000")).
+	Background(lipgloss.Color("#FFFFFF"))
+
+// Screen that shows a token as a QR code, to scan it with another app
+type QRCodeScreen struct {
+	// Vault
+	vault *tlockvault.Vault
+
+	// Token to show
+	token tlockvault.Token
+
+	// Current step
+	step int
+
+	// Password input, the QR code gives away the secret so the password is asked again
+	password textinput.Model
+
+	// Path of the PNG
+	path textinput.Model
+
+	// Rendered QR code
+	qrCode string
+
+	// Error
+	errorMessage *error
+}
+
+// Initializes the QR code screen
+func InitializeQRCodeScreen(vault *tlockvault.Vault, token tlockvault.Token) QRCodeScreen {
+	// Input boxes
+	password := components.InitializeInputBox("Your password goes here...")
+	password.EchoMode = textinput.EchoPassword
+	password.EchoCharacter = constants.CHAR_ECHO
+	password.Focus()
+
+	path := components.InitializeInputBox("Path of the image goes here...")
+
+	return QRCodeScreen{
+		vault:    vault,
+		token:    token,
+		password: password,
+		path:     path,
+	}
+}
+
+// Default name of the PNG, from the issuer and account of the token
+func qrCodeFileName(token tlockvault.Token) string {
+	name := strings.Trim(strings.Join([]string{token.Issuer, token.Account}, "-"), "-")
+
+	// Keep the name safe to use on any filesystem
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+
+		return '_'
+	}, name)
+
+	if name == "" {
+		name = "token"
+	}
+
+	return name + ".png"
+}
+
+// Init
+func (screen QRCodeScreen) Init() tea.Cmd {
+	return nil
+}
+
+// Update
+func (screen QRCodeScreen) Update(msg tea.Msg, manager *modelmanager.ModelManager) (modelmanager.Screen, tea.Cmd) {
+	var cmd tea.Cmd
+
+	msgType, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return screen, nil
+	}
+
+	switch screen.step {
+	case qrCodeStepPassword:
+		switch {
+		case strings.Contains(msgType.String(), "tab"):
+			// We ignore tabs (because of bubbletea issue in windows)
+
+		case key.Matches(msgType, qrCodePasswordKeys.GoBack):
+			manager.PopScreen()
+
+		case key.Matches(msgType, qrCodePasswordKeys.Enter):
+			if !screen.vault.VerifyPassword(screen.password.Value()) {
+				err := tlockvault.ERR_PASSWORD_INVALID
+				screen.errorMessage = &err
+				break
+			}
+
+			// The password is not needed anymore
+			screen.password.SetValue("")
+			screen.password.Blur()
+
+			// Render
+			qrCode, err := utils.RenderQRCode(screen.token.URI())
+			if err != nil {
+				screen.errorMessage = &err
+				break
+			}
+
+			screen.errorMessage = nil
+			screen.qrCode = qrCodeStyle.Render(qrCode)
+			screen.step = qrCodeStepShow
+
+		default:
+			screen.password, _ = screen.password.Update(msg)
+		}
+
+	case qrCodeStepShow:
+		switch {
+		case key.Matches(msgType, qrCodeShowKeys.GoBack):
+			manager.PopScreen()
+
+		case key.Matches(msgType, qrCodeShowKeys.SavePNG):
+			screen.step = qrCodeStepPNG
+			screen.path.SetValue("~/" + qrCodeFileName(screen.token))
+			screen.path.CursorEnd()
+			screen.path.Focus()
+		}
+
+	case qrCodeStepPNG:
+		switch {
+		case strings.Contains(msgType.String(), "tab"):
+			// We ignore tabs (because of bubbletea issue in windows)
+
+		case key.Matches(msgType, qrCodePNGKeys.GoBack):
+			screen.errorMessage = nil
+			screen.step = qrCodeStepShow
+			screen.path.Blur()
+
+		case key.Matches(msgType, qrCodePNGKeys.Enter):
+			path := strings.TrimSpace(screen.path.Value())
+
+			// Write
+			data, err := utils.QRCodePNG(screen.token.URI())
+			if err == nil {
+				err = utils.WriteFileAtomic(utils.ExpandHome(path), data, 0o600)
+			}
+
+			if err != nil {
+				screen.errorMessage = &err
+				break
+			}
+
+			screen.errorMessage = nil
+			screen.step = qrCodeStepShow
+			screen.path.Blur()
+
+			cmd = func() tea.Msg {
+				return components.StatusBarMsg{Message: fmt.Sprintf("Saved the QR code to %s, delete it once it is scanned", path)}
+			}
+
+		default:
+			screen.path, _ = screen.path.Update(msg)
+		}
+	}
+
+	return screen, cmd
+}
+
+// View
+func (screen QRCodeScreen) View() string {
+	accountName := screen.token.Account
+
+	if accountName == "" {
+		accountName = "<no account name>"
+	}
+
+	items := []string{
+		tlockstyles.Title(qrCodeAscii), "",
+	}
+
+	switch screen.step {
+	case qrCodeStepPassword:
+		items = append(
+			items,
+			tlockstyles.Dimmed(fmt.Sprintf("Show %s as a QR code", accountName)), "",
+			components.InputGroup("Password", "The QR code reveals the secret of the token, enter your password to show it", screen.errorMessage, screen.password),
+			tlockstyles.HelpView(qrCodePasswordKeys),
+		)
+
+	case qrCodeStepShow:
+		items = append(
+			items,
+			tlockstyles.Dimmed(fmt.Sprintf("Scan to add %s to another app", accountName)), "",
+			screen.qrCode, "",
+			tlockstyles.HelpView(qrCodeShowKeys),
+		)
+
+	case qrCodeStepPNG:
+		items = append(
+			items,
+			tlockstyles.Dimmed("Anyone with the image can generate your codes"), "",
+			components.InputGroup("File", "Path of the PNG image to save the QR code to", screen.errorMessage, screen.path),
+			tlockstyles.HelpView(qrCodePNGKeys),
+		)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center, items...)
+}
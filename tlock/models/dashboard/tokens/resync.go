@@ -0,0 +1,192 @@
+package tokens
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/eklairs/tlock/tlock-internal/components"
+	tlockmessages "github.com/eklairs/tlock/tlock-internal/messages"
+	"github.com/eklairs/tlock/tlock-internal/modelmanager"
+	"github.com/eklairs/tlock/tlock-internal/utils"
+	tlockotp "github.com/eklairs/tlock/tlock-otp"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	tlockstyles "github.com/eklairs/tlock/tlock/styles"
+)
+
+var resyncAscii = `
+█▀█ █▀▀ █▀ █▄█ █▄ █ █▀▀
+█▀▄ ██▄ ▄█  █  █ ▀█ █▄▄`
+
+// Resync key map
+type resyncKeyMap struct {
+	Tab    key.Binding
+	Enter  key.Binding
+	GoBack key.Binding
+}
+
+// ShortHelp()
+func (k resyncKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Tab, k.Enter, k.GoBack}
+}
+
+// FullHelp()
+func (k resyncKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Tab},
+		{k.Enter},
+		{k.GoBack},
+	}
+}
+
+// Keys
+var resyncKeys = resyncKeyMap{
+	Tab: key.NewBinding(
+		key.WithKeys("tab", "shift+tab"),
+		key.WithHelp("tab/shift+tab", "switch input"),
+	),
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "resync"),
+	),
+	GoBack: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+}
+
+// Screen to resynchronise the counter of a HOTP token from the codes the server accepted
+type ResyncTokenScreen struct {
+	// Vault
+	vault *tlockvault.Vault
+
+	// Folder of the token
+	folder tlockvault.Folder
+
+	// Token to resync
+	token tlockvault.Token
+
+	// Number of counters searched ahead of the current one
+	lookahead int
+
+	// First code
+	firstCode textinput.Model
+
+	// Code right after the first one
+	secondCode textinput.Model
+
+	// Error
+	errorMessage *error
+}
+
+// Initializes the screen to resync a HOTP token
+func InitializeResyncTokenScreen(vault *tlockvault.Vault, folder tlockvault.Folder, token tlockvault.Token, lookahead int) ResyncTokenScreen {
+	// Input boxes
+	firstCode := utils.ValidatorInteger(components.InitializeInputBox("First code goes here..."))
+	firstCode.Focus()
+
+	secondCode := utils.ValidatorInteger(components.InitializeInputBox("Second code goes here..."))
+
+	if lookahead <= 0 {
+		lookahead = tlockotp.DefaultLookahead
+	}
+
+	return ResyncTokenScreen{
+		vault:      vault,
+		folder:     folder,
+		token:      token,
+		lookahead:  lookahead,
+		firstCode:  firstCode,
+		secondCode: secondCode,
+	}
+}
+
+// Init
+func (screen ResyncTokenScreen) Init() tea.Cmd {
+	return nil
+}
+
+// Update
+func (screen ResyncTokenScreen) Update(msg tea.Msg, manager *modelmanager.ModelManager) (modelmanager.Screen, tea.Cmd) {
+	var cmd tea.Cmd
+
+	msgType, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return screen, nil
+	}
+
+	switch {
+	case key.Matches(msgType, resyncKeys.GoBack):
+		manager.PopScreen()
+
+	case key.Matches(msgType, resyncKeys.Tab):
+		if screen.firstCode.Focused() {
+			screen.firstCode.Blur()
+			screen.secondCode.Focus()
+		} else {
+			screen.firstCode.Focus()
+			screen.secondCode.Blur()
+		}
+
+	case key.Matches(msgType, resyncKeys.Enter):
+		counter, err := tlockotp.Resync(screen.token, []string{screen.firstCode.Value(), screen.secondCode.Value()}, screen.lookahead)
+		if err != nil {
+			screen.errorMessage = &err
+			break
+		}
+
+		// Fix the counter
+		if err := screen.vault.SetCounter(screen.folder.Name, screen.token.ID, counter); err != nil {
+			screen.errorMessage = &err
+			break
+		}
+
+		accountName := screen.token.Account
+		if accountName == "" {
+			accountName = "<no account name>"
+		}
+
+		skipped := counter - screen.token.Counter()
+
+		cmd = tea.Batch(
+			func() tea.Msg { return tlockmessages.RefreshTokensMsg{} },
+			func() tea.Msg {
+				return components.StatusBarMsg{Message: fmt.Sprintf("Successfully resynced %s, the counter moved ahead by %d", accountName, skipped)}
+			},
+		)
+
+		manager.PopScreen()
+
+	default:
+		// Update input boxes
+		if screen.firstCode.Focused() {
+			screen.firstCode, _ = screen.firstCode.Update(msg)
+		}
+
+		if screen.secondCode.Focused() {
+			screen.secondCode, _ = screen.secondCode.Update(msg)
+		}
+	}
+
+	return screen, cmd
+}
+
+// View
+func (screen ResyncTokenScreen) View() string {
+	accountName := screen.token.Account
+
+	if accountName == "" {
+		accountName = "<no account name>"
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Center,
+		tlockstyles.Title(resyncAscii), "",
+		tlockstyles.Dimmed(fmt.Sprintf("Resync the counter of %s with the server", accountName)), "",
+		components.InputGroup("First code", fmt.Sprintf("A code the server accepted, searched up to %d counters ahead", screen.lookahead), screen.errorMessage, screen.firstCode),
+		components.InputGroup("Second code", "The code accepted right after it, optional", nil, screen.secondCode),
+		tlockstyles.HelpView(resyncKeys),
+	)
+}
@@ -5,24 +5,23 @@ import (
 	"io"
 	"math"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/eklairs/tlock/tlock-internal/clipboard"
 	"github.com/eklairs/tlock/tlock-internal/components"
 	"github.com/eklairs/tlock/tlock-internal/context"
 	tlockmessages "github.com/eklairs/tlock/tlock-internal/messages"
 	"github.com/eklairs/tlock/tlock-internal/modelmanager"
 	"github.com/eklairs/tlock/tlock-internal/utils"
+	tlockotp "github.com/eklairs/tlock/tlock-otp"
 	tlockvault "github.com/eklairs/tlock/tlock-vault"
 	tlockstyles "github.com/eklairs/tlock/tlock/styles"
-	"github.com/pquerna/otp"
-	"github.com/pquerna/otp/hotp"
-	"github.com/pquerna/otp/totp"
 	"golang.org/x/term"
 )
 
@@ -31,31 +30,6 @@ func tokensWidth(width int) int {
 	return width - int(math.Floor((1.0/5.0)*float64(width)))
 }
 
-// Returns the remaining time
-func getRemainingTime(token tlockvault.Token) int {
-	return int(token.Period - int(time.Now().Unix())%token.Period)
-}
-
-// Returns the current code
-func getCurrentCode(token tlockvault.Token) string {
-	var code string
-
-	if token.Type == tlockvault.TokenTypeTOTP {
-		code, _ = totp.GenerateCodeCustom(token.Secret, time.Now(), totp.ValidateOpts{
-			Period:    uint(token.Period),
-			Digits:    otp.Digits(token.Digits),
-			Algorithm: token.HashingAlgorithm,
-		})
-	} else {
-		code, _ = hotp.GenerateCodeCustom(token.Secret, uint64(token.UsageCounter+token.InitialCounter), hotp.ValidateOpts{
-			Digits:    otp.Digits(token.Digits),
-			Algorithm: token.HashingAlgorithm,
-		})
-	}
-
-	return code
-}
-
 // Token list item
 type tokensListItem struct {
 	// Current code
@@ -73,43 +47,54 @@ func (item tokensListItem) FilterValue() string {
 	return ""
 }
 
+// Returns the current code of the token and the seconds before it changes
+// The tokens of a vault served by the agent have no secrets, so the agent generates their codes
+func currentCode(vault *tlockvault.Vault, token tlockvault.Token) (string, int, error) {
+	if remote := vault.Remote(); remote != nil {
+		return remote.Code(token.ID)
+	}
+
+	return tlockotp.CurrentCode(token), tlockotp.RemainingTime(token), nil
+}
+
 // Refreshes the token
-func (item *tokensListItem) Refresh() {
-	// If the token is a totp, then update the time
-	if item.Token.Type == tlockvault.TokenTypeTOTP {
-		timeToRefresh := getRemainingTime(item.Token)
+// The code is blanked if it cannot be generated
+func (item *tokensListItem) Refresh(vault *tlockvault.Vault) error {
+	code, timeToRefresh, err := currentCode(vault, item.Token)
+
+	if err != nil {
+		code = strings.Repeat("-", item.Token.Digits)
+	}
+
+	// If the token is time based, then update the time
+	if item.Token.Type.TimeBased() {
 		item.time = &timeToRefresh
 	}
 
 	// Update current code
-	item.CurrentCode = getCurrentCode(item.Token)
+	item.CurrentCode = code
+
+	return err
 }
 
 // Initializes a new instance of the tokens list item
-func InitializeTokenListItem(token tlockvault.Token) tokensListItem {
-	var ttr *int
+func InitializeTokenListItem(vault *tlockvault.Vault, token tlockvault.Token) tokensListItem {
+	item := tokensListItem{Token: token}
+	item.Refresh(vault)
 
-	if token.Type == tlockvault.TokenTypeTOTP {
-		timeToRefresh := getRemainingTime(token)
-		ttr = &timeToRefresh
-	}
-
-	return tokensListItem{
-		CurrentCode: getCurrentCode(token),
-		Token:       token,
-		time:        ttr,
-	}
+	return item
 }
 
 // Tokens key map
 type tokenKeyMap struct {
 	Manual key.Binding
 	Screen key.Binding
+	File   key.Binding
 }
 
 // ShortHelp()
 func (k tokenKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Manual, k.Screen}
+	return []key.Binding{k.Manual, k.Screen, k.File}
 }
 
 // FullHelp()
@@ -117,6 +102,7 @@ func (k tokenKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Manual},
 		{k.Screen},
+		{k.File},
 	}
 }
 
@@ -209,10 +195,63 @@ type Tokens struct {
 	// Tokens
 	listview *list.Model
 
+	// ID of the token to focus once its folder is shown
+	restoreToken string
+
 	// Context
 	context *context.Context
 }
 
+// Returns the delay after which the copied code of the token is cleared from the clipboard
+// It defaults to the period of the token, which is when the code expires
+func (tokens Tokens) clipboardClearDelay(token tlockvault.Token) time.Duration {
+	if delay := tokens.context.Config.ClipboardClear; delay != nil {
+		return delay.Duration
+	}
+
+	// HOTP tokens do not expire, but they still should not stay on the clipboard forever
+	if token.Period <= 0 {
+		return 30 * time.Second
+	}
+
+	return time.Duration(token.Period) * time.Second
+}
+
+// Reports the copy in the status bar, and clears the code from the clipboard after the delay
+// Backends that cannot read the clipboard back clear it even if something else has been copied since
+func (tokens Tokens) clearClipboardLater(backend clipboard.Backend, code string, token tlockvault.Token, accountName string) tea.Cmd {
+	message := fmt.Sprintf("Successfully copied token (%s)", accountName)
+
+	// Clearing is disabled
+	delay := tokens.clipboardClearDelay(token)
+
+	if delay <= 0 {
+		return func() tea.Msg { return components.StatusBarMsg{Message: message} }
+	}
+
+	copied := func() tea.Msg {
+		return components.StatusBarMsg{
+			Message:   message,
+			Countdown: &components.Countdown{Label: "clearing the clipboard", Until: time.Now().Add(delay)},
+		}
+	}
+
+	clearLater := func() tea.Msg {
+		if clipboard.ClearAfter(backend, code, delay) {
+			return components.StatusBarMsg{Message: "Cleared the copied token from the clipboard"}
+		}
+
+		return nil
+	}
+
+	return tea.Batch(copied, clearLater)
+}
+
+// Focuses the token with the given ID once the folder containing it is shown
+func (tokens *Tokens) RestoreSelection(id string) {
+	tokens.restoreToken = id
+}
+
 // Returns the focused folder item
 func (tokens Tokens) Focused() *tokensListItem {
 	// If there are no items, return nil
@@ -228,20 +267,20 @@ func (tokens Tokens) Focused() *tokensListItem {
 }
 
 // Builds the token list view items
-func buildTokensItems(tokens []tlockvault.Token) []list.Item {
+func buildTokensItems(vault *tlockvault.Vault, tokens []tlockvault.Token) []list.Item {
 	mapper := func(token tlockvault.Token) list.Item {
-		return InitializeTokenListItem(token)
+		return InitializeTokenListItem(vault, token)
 	}
 
 	return utils.Map(tokens, mapper)
 }
 
 // Builds the tokens list view
-func buildTokensListView(tokens []tlockvault.Token, context *context.Context) list.Model {
+func buildTokensListView(vault *tlockvault.Vault, tokens []tlockvault.Token, context *context.Context) list.Model {
 	// Get terminal size
 	width, height, _ := term.GetSize(int(os.Stdout.Fd()))
 
-	return components.ListViewSimple(buildTokensItems(tokens), tokensListDelegate{context: context}, tokensWidth(width), height-5)
+	return components.ListViewSimple(buildTokensItems(vault, tokens), tokensListDelegate{context: context}, tokensWidth(width), height-5)
 }
 
 // Initializes a new instance of folders
@@ -256,6 +295,10 @@ func InitializeTokens(vault *tlockvault.Vault, context *context.Context) Tokens
 			key.WithKeys(context.Config.Tokens.AddScreen.Keys()...),
 			key.WithHelp(strings.Join(context.Config.Tokens.AddScreen.Keys(), "/"), "add token from screen"),
 		),
+		File: key.NewBinding(
+			key.WithKeys(context.Config.Tokens.AddFile.Keys()...),
+			key.WithHelp(strings.Join(context.Config.Tokens.AddFile.Keys(), "/"), "add tokens from image"),
+		),
 	}
 
 	return Tokens{
@@ -265,34 +308,52 @@ func InitializeTokens(vault *tlockvault.Vault, context *context.Context) Tokens
 	}
 }
 
+// Returns the bindings that change the vault or need the secrets of the tokens
+func (tokens Tokens) changeBindings() []key.Binding {
+	config := tokens.context.Config.Tokens
+
+	return []key.Binding{
+		config.Add.Binding, config.Edit.Binding, config.Move.Binding, config.ShowQR.Binding, config.Delete.Binding, config.MoveDown.Binding,
+		config.MoveUp.Binding, config.NextHOTP.Binding, config.ResyncHOTP.Binding, config.AddScreen.Binding, config.AddFile.Binding,
+	}
+}
+
 // Handles update messages
 func (tokens *Tokens) Update(msg tea.Msg, manager *modelmanager.ModelManager) tea.Cmd {
 	cmds := make([]tea.Cmd, 0)
 
 	switch msgType := msg.(type) {
 	case tea.KeyMsg:
+		// The agent only serves the codes
+		if tokens.vault.Remote() != nil && key.Matches(msgType, tokens.changeBindings()...) {
+			return components.StatusBarError(tlockvault.ERR_VAULT_REMOTE)
+		}
+
 		switch {
 		case key.Matches(msgType, tokens.context.Config.Tokens.Copy.Binding):
-			if clipboard.Unsupported {
-				cmds = append(cmds, func() tea.Msg {
-					return components.StatusBarMsg{Message: "Clipboard is not available", ErrorMessage: true}
-				})
-			} else {
-				if focused := tokens.Focused(); focused != nil {
-					// Set clipboard
-					clipboard.WriteAll(focused.CurrentCode)
+			if focused := tokens.Focused(); focused != nil {
+				accountName := focused.Token.Account
 
-					accountName := focused.Token.Account
+				if accountName == "" {
+					accountName = "<no account name>"
+				}
 
-					if accountName == "" {
-						accountName = "<no account name>"
-					}
+				// Pick clipboard
+				clipboardConfig := tokens.context.Config.Clipboard
+				backend, err := clipboard.Select(clipboardConfig.Backend, clipboardConfig.CopyCommand, clipboardConfig.PasteCommand)
 
+				// Set clipboard
+				if err == nil {
+					err = backend.WriteSecret(focused.CurrentCode)
+				}
+
+				if err != nil {
 					cmds = append(cmds, func() tea.Msg {
-						return components.StatusBarMsg{Message: fmt.Sprintf("Successfully copied token (%s)", accountName)}
+						return components.StatusBarMsg{Message: fmt.Sprintf("Failed to copy token: %s", err), ErrorMessage: true}
 					})
+				} else {
+					cmds = append(cmds, tokens.clearClipboardLater(backend, focused.CurrentCode, focused.Token, accountName))
 				}
-
 			}
 
 		case key.Matches(msgType, tokens.context.Config.Tokens.Add.Binding):
@@ -310,6 +371,11 @@ func (tokens *Tokens) Update(msg tea.Msg, manager *modelmanager.ModelManager) te
 				manager.PushScreen(InitializeMoveTokenScreen(tokens.vault, *tokens.folder, focused.Token))
 			}
 
+		case key.Matches(msgType, tokens.context.Config.Tokens.ShowQR.Binding):
+			if focused := tokens.Focused(); focused != nil {
+				manager.PushScreen(InitializeQRCodeScreen(tokens.vault, focused.Token))
+			}
+
 		case key.Matches(msgType, tokens.context.Config.Tokens.Delete.Binding):
 			if focused := tokens.Focused(); focused != nil {
 				manager.PushScreen(InitializeDeleteTokenScreen(tokens.vault, *tokens.folder, focused.Token))
@@ -318,7 +384,10 @@ func (tokens *Tokens) Update(msg tea.Msg, manager *modelmanager.ModelManager) te
 		case key.Matches(msgType, tokens.context.Config.Tokens.MoveDown.Binding):
 			if focused := tokens.Focused(); focused != nil {
 				// Move token down
-				tokens.vault.MoveTokenDown(tokens.folder.Name, focused.Token)
+				if _, err := tokens.vault.MoveTokenDown(tokens.folder.Name, focused.Token.ID); err != nil {
+					cmds = append(cmds, components.StatusBarError(err))
+					break
+				}
 
 				// Refresh tokens
 				cmds = append(cmds, func() tea.Msg { return tlockmessages.RefreshTokensMsg{} })
@@ -340,7 +409,10 @@ func (tokens *Tokens) Update(msg tea.Msg, manager *modelmanager.ModelManager) te
 		case key.Matches(msgType, tokens.context.Config.Tokens.MoveUp.Binding):
 			if focused := tokens.Focused(); focused != nil {
 				// Move token down
-				tokens.vault.MoveTokenUp(tokens.folder.Name, focused.Token)
+				if _, err := tokens.vault.MoveTokenUp(tokens.folder.Name, focused.Token.ID); err != nil {
+					cmds = append(cmds, components.StatusBarError(err))
+					break
+				}
 
 				// Refresh tokens
 				cmds = append(cmds, func() tea.Msg { return tlockmessages.RefreshTokensMsg{} })
@@ -362,7 +434,10 @@ func (tokens *Tokens) Update(msg tea.Msg, manager *modelmanager.ModelManager) te
 		case key.Matches(msgType, tokens.context.Config.Tokens.NextHOTP.Binding):
 			if focused := tokens.Focused(); focused != nil {
 				if focused.Token.Type == tlockvault.TokenTypeHOTP {
-					tokens.vault.IncreaseCounter(tokens.folder.Name, focused.Token)
+					if err := tokens.vault.IncreaseCounter(tokens.folder.Name, focused.Token.ID); err != nil {
+						cmds = append(cmds, components.StatusBarError(err))
+						break
+					}
 
 					accountName := focused.Token.Account
 					if accountName == "" {
@@ -377,15 +452,36 @@ func (tokens *Tokens) Update(msg tea.Msg, manager *modelmanager.ModelManager) te
 				}
 			}
 
+		case key.Matches(msgType, tokens.context.Config.Tokens.ResyncHOTP.Binding):
+			if focused := tokens.Focused(); focused != nil {
+				if focused.Token.Type == tlockvault.TokenTypeHOTP {
+					manager.PushScreen(InitializeResyncTokenScreen(tokens.vault, *tokens.folder, focused.Token, tokens.context.Config.HOTPLookahead))
+				}
+			}
+
 		case key.Matches(msgType, tokens.context.Config.Tokens.AddScreen.Binding):
 			if tokens.folder != nil {
-				cmds = append(cmds, manager.PushScreen(InitializeTokenFromScreen(tokens.vault, *tokens.folder)))
+				cmds = append(cmds, manager.PushScreen(InitializeTokenFromScreen(tokens.vault, *tokens.folder, tokens.context.Config.ScreenCapture)))
+			}
+
+		case key.Matches(msgType, tokens.context.Config.Tokens.AddFile.Binding):
+			if tokens.folder != nil {
+				manager.PushScreen(InitializeTokenFromFileScreen(tokens.vault, *tokens.folder))
 			}
 		}
 
 	case tlockmessages.FolderChanged:
 		// Build listview
-		listview := buildTokensListView(tokens.vault.GetTokens(msgType.Folder.Name), tokens.context)
+		listview := buildTokensListView(tokens.vault, tokens.vault.GetTokens(msgType.Folder.Name), tokens.context)
+
+		// Focus the token to restore
+		if tokens.restoreToken != "" {
+			if index := slices.IndexFunc(tokens.vault.GetTokens(msgType.Folder.Name), func(token tlockvault.Token) bool { return token.ID == tokens.restoreToken }); index != -1 {
+				listview.Select(index)
+			}
+
+			tokens.restoreToken = ""
+		}
 
 		// Update listview
 		tokens.listview = &listview
@@ -395,14 +491,24 @@ func (tokens *Tokens) Update(msg tea.Msg, manager *modelmanager.ModelManager) te
 		if tokens.listview != nil {
 			items := make([]list.Item, len(tokens.listview.Items()))
 
+			// Any error of the agent, reported once for all the tokens
+			var refreshErr error
+
 			for index, item := range tokens.listview.Items() {
 				tokenItem := item.(tokensListItem)
-				tokenItem.Refresh()
+
+				if err := tokenItem.Refresh(tokens.vault); err != nil {
+					refreshErr = err
+				}
 
 				items[index] = tokenItem
 			}
 
 			cmds = append(cmds, tokens.listview.SetItems(items))
+
+			if refreshErr != nil {
+				cmds = append(cmds, components.StatusBarError(refreshErr))
+			}
 		}
 
 	case tea.WindowSizeMsg:
@@ -413,7 +519,7 @@ func (tokens *Tokens) Update(msg tea.Msg, manager *modelmanager.ModelManager) te
 
 	case tlockmessages.RefreshTokensMsg:
 		if tokens.folder != nil {
-			cmds = append(cmds, tokens.listview.SetItems(buildTokensItems(tokens.vault.GetTokens(tokens.folder.Name))))
+			cmds = append(cmds, tokens.listview.SetItems(buildTokensItems(tokens.vault, tokens.vault.GetTokens(tokens.folder.Name))))
 		}
 	}
 
@@ -0,0 +1,133 @@
+package dashboard
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/eklairs/tlock/tlock-internal/components"
+	tlockmessages "github.com/eklairs/tlock/tlock-internal/messages"
+	"github.com/eklairs/tlock/tlock-internal/modelmanager"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
+	tlockstyles "github.com/eklairs/tlock/tlock/styles"
+)
+
+var vaultChangedAsciiArt = `
+█▀ █▄█ █▄ █ █▀▀
+▄█  █  █ ▀█ █▄▄`
+
+// Vault changed key map
+type vaultChangedKeyMap struct {
+	Reload key.Binding
+	Merge  key.Binding
+	Ignore key.Binding
+}
+
+// ShortHelp()
+func (k vaultChangedKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Reload, k.Merge, k.Ignore}
+}
+
+// FullHelp()
+func (k vaultChangedKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Reload},
+		{k.Merge},
+		{k.Ignore},
+	}
+}
+
+// Keys
+var vaultChangedKeys = vaultChangedKeyMap{
+	Reload: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "reload"),
+	),
+	Merge: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "merge"),
+	),
+	Ignore: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "ignore"),
+	),
+}
+
+// Screen shown when the vault file was changed on the disk by someone else
+type VaultChangedScreen struct {
+	// Vault
+	vault *tlockvault.Vault
+
+	// Any error while reloading
+	err error
+}
+
+// Initializes a new instance of the vault changed screen
+func InitializeVaultChangedScreen(vault *tlockvault.Vault) VaultChangedScreen {
+	return VaultChangedScreen{
+		vault: vault,
+	}
+}
+
+// Init
+func (screen VaultChangedScreen) Init() tea.Cmd {
+	return nil
+}
+
+// Update
+func (screen VaultChangedScreen) Update(msg tea.Msg, manager *modelmanager.ModelManager) (modelmanager.Screen, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msgType := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msgType, vaultChangedKeys.Ignore):
+			screen.vault.IgnoreDiskChanges()
+
+			manager.PopScreen()
+
+		case key.Matches(msgType, vaultChangedKeys.Reload):
+			if screen.err = screen.vault.Reload(); screen.err == nil {
+				cmd = refreshAfterSync("Reloaded the vault from the disk")
+
+				manager.PopScreen()
+			}
+
+		case key.Matches(msgType, vaultChangedKeys.Merge):
+			if screen.err = screen.vault.MergeFromDisk(); screen.err == nil {
+				cmd = refreshAfterSync("Merged the changes from the disk into the vault")
+
+				manager.PopScreen()
+			}
+		}
+	}
+
+	return screen, cmd
+}
+
+// Refreshes the folders and tokens after the vault has been synced with the disk
+func refreshAfterSync(message string) tea.Cmd {
+	return tea.Sequence(
+		func() tea.Msg { return tlockmessages.RefreshFoldersMsg{} },
+		func() tea.Msg { return tlockmessages.RequestFolderChanged{} },
+		func() tea.Msg { return components.StatusBarMsg{Message: message} },
+	)
+}
+
+// View
+func (screen VaultChangedScreen) View() string {
+	items := []string{
+		tlockstyles.Title(vaultChangedAsciiArt), "",
+		tlockstyles.Dimmed("The vault has been changed on the disk by another program"), "",
+		tlockstyles.Dimmed("Reload to use the version on the disk, or merge to keep the tokens from both"), "",
+	}
+
+	// Show error, if any
+	if screen.err != nil {
+		items = append(items, tlockstyles.Styles.Error.Render("× "+screen.err.Error()), "")
+	}
+
+	// Add help
+	items = append(items, tlockstyles.HelpView(vaultChangedKeys))
+
+	return lipgloss.JoinVertical(lipgloss.Center, items...)
+}
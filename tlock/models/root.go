@@ -1,11 +1,19 @@
 package tlockmodels
 
 import (
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/eklairs/tlock/tlock-internal/components"
 	"github.com/eklairs/tlock/tlock-internal/context"
+	"github.com/eklairs/tlock/tlock-internal/crash"
 	"github.com/eklairs/tlock/tlock-internal/modelmanager"
 	"github.com/eklairs/tlock/tlock/models/auth"
+	"github.com/eklairs/tlock/tlock/models/dashboard"
+
+	tlockcore "github.com/eklairs/tlock/tlock-core"
+	tlockvault "github.com/eklairs/tlock/tlock-vault"
 
 	tlockmessages "github.com/eklairs/tlock/tlock-internal/messages"
 )
@@ -13,6 +21,15 @@ import (
 // Root model
 type RootModel struct {
 	manager modelmanager.ModelManager
+
+	// Context
+	context *context.Context
+
+	// Whether a vault is unlocked in the dashboard
+	unlocked bool
+
+	// Time of the last key press or mouse input
+	lastInput time.Time
 }
 
 // Initializes a new instance of the root model
@@ -28,6 +45,7 @@ func InitializeRootModel(context *context.Context) RootModel {
 
 	return RootModel{
 		manager: modelmanager.New(screen),
+		context: context,
 	}
 }
 
@@ -42,22 +60,63 @@ func (model RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		model.lastInput = time.Now()
+
 		switch msg.String() {
 		case "ctrl+c", "ctrl+q":
 			cmds = append(cmds, tea.Quit)
 		}
 
+	case tea.MouseMsg:
+		model.lastInput = time.Now()
+
+	// Start watching for idleness
+	case tlockmessages.VaultUnlockedMsg:
+		model.unlocked = true
+		model.lastInput = time.Now()
+
 	// We dispatch back the message from root model because its the only model that recieves all the models everytime.
 	// If a new screen is pushed to modelmanager, the dashboard will not recieve the message and thus will break the update
 	case tlockmessages.RefreshTokensValue:
-		cmds = append(cmds, tlockmessages.DispatchRefreshTokensValueMsg())
+		// Nothing to refresh once the vault is locked, let the ticks stop until the dashboard starts them again
+		if !model.unlocked {
+			break
+		}
+
+		// Lock if idle for too long
+		if timeout := model.context.Config.AutoLock.Duration; timeout > 0 && time.Since(model.lastInput) >= timeout {
+			model.unlocked = false
+
+			cmds = append(cmds, func() tea.Msg { return tlockmessages.LockVaultMsg{} })
+		} else {
+			cmds = append(cmds, tlockmessages.DispatchRefreshTokensValueMsg())
+		}
+
+	// Replace every screen with the lock screen, going back leads to the user selection
+	// The dashboard comes back with the same selection after unlocking
+	case tlockmessages.VaultLockedMsg:
+		next := func(username string, vault *tlockvault.Vault, context *context.Context) modelmanager.Screen {
+			return dashboard.InitializeDashboardScreenWithSelection(username, vault, context, msg.Folder, msg.Token)
+		}
+
+		cmds = append(cmds, model.manager.ResetScreens(
+			auth.InitializeSelectUserScreen(model.context),
+			auth.InitializeLockScreen(model.context, tlockcore.User(msg.Username), next, msg.Err),
+		))
+
+	// Same goes for the vault write errors, they must reach the status bar whichever screen is on top
+	case tlockmessages.VaultWriteFailedMsg:
+		cmds = append(cmds, tlockmessages.ListenVaultWriteErrors(msg.Vault), func() tea.Msg {
+			return components.StatusBarMsg{Message: msg.Err.Error(), ErrorMessage: true}
+		})
 	}
 
 	// Update model manager
 	cmds = append(cmds, model.manager.Update(msg))
 
-	// Return
-	return model, tea.Batch(cmds...)
+	// Every command runs in its own goroutine, where bubbletea does not recover the panics
+	// Wrapping them here covers the commands of all the screens
+	return model, crash.Cmd(tea.Batch(cmds...))
 }
 
 // View